@@ -14,6 +14,10 @@ type QueuedQuery struct {
 	arguments []any
 	fn        batchItemFunc
 	sd        *pgconn.StatementDescription
+	err       error // set when the query could not be sent in a batch with IsolateErrors
+
+	// abandon is called with the batch error if the batch fails before fn is called.
+	abandon func(err error)
 }
 
 type batchItemFunc func(br BatchResults) error
@@ -54,10 +58,176 @@ func (qq *QueuedQuery) Exec(fn func(ct pgconn.CommandTag) error) {
 	}
 }
 
+// ErrBatchResultNotResolved is returned by BatchResult.Get when the results of the query have not been read yet.
+var ErrBatchResultNotResolved = errors.New("batch result not resolved")
+
+// BatchResult is the typed result of a query queued with QueueCollect, QueueCollectOneRow, QueueCollectExactlyOneRow,
+// or QueueExec. It is resolved when the results of the query are read, at the latest when BatchResults.Close is called.
+// If the batch fails before the results of the query are read, the result is resolved with the batch error.
+type BatchResult[T any] struct {
+	value    T
+	err      error
+	resolved bool
+}
+
+// Get returns the value and error of the query. ErrBatchResultNotResolved is returned if the results of the query have
+// not been read yet.
+func (r *BatchResult[T]) Get() (T, error) {
+	if !r.resolved {
+		var zero T
+		return zero, ErrBatchResultNotResolved
+	}
+	return r.value, r.err
+}
+
+// Resolved returns true if the results of the query have been read.
+func (r *BatchResult[T]) Resolved() bool {
+	return r.resolved
+}
+
+func (r *BatchResult[T]) resolve(value T, err error) error {
+	r.value = value
+	r.err = err
+	r.resolved = true
+	return err
+}
+
+func (r *BatchResult[T]) abandon(err error) {
+	if !r.resolved {
+		var zero T
+		r.resolve(zero, err)
+	}
+}
+
+// QueueCollect queues sql with args to b and returns a *BatchResult that is resolved with all rows of the result
+// collected with fn as with CollectRows.
+func QueueCollect[T any](b *Batch, fn RowToFunc[T], sql string, args ...any) *BatchResult[[]T] {
+	r := &BatchResult[[]T]{}
+	qq := b.Queue(sql, args...)
+	qq.Query(func(rows Rows) error {
+		return r.resolve(CollectRows(rows, fn))
+	})
+	qq.abandon = r.abandon
+	return r
+}
+
+// QueueCollectOneRow queues sql with args to b and returns a *BatchResult that is resolved with the first row of the
+// result collected with fn as with CollectOneRow.
+func QueueCollectOneRow[T any](b *Batch, fn RowToFunc[T], sql string, args ...any) *BatchResult[T] {
+	r := &BatchResult[T]{}
+	qq := b.Queue(sql, args...)
+	qq.Query(func(rows Rows) error {
+		return r.resolve(CollectOneRow(rows, fn))
+	})
+	qq.abandon = r.abandon
+	return r
+}
+
+// QueueCollectExactlyOneRow queues sql with args to b and returns a *BatchResult that is resolved with the only row of
+// the result collected with fn as with CollectExactlyOneRow.
+func QueueCollectExactlyOneRow[T any](b *Batch, fn RowToFunc[T], sql string, args ...any) *BatchResult[T] {
+	r := &BatchResult[T]{}
+	qq := b.Queue(sql, args...)
+	qq.Query(func(rows Rows) error {
+		return r.resolve(CollectExactlyOneRow(rows, fn))
+	})
+	qq.abandon = r.abandon
+	return r
+}
+
+// QueueExec queues sql with args to b and returns a *BatchResult that is resolved with the command tag of the query.
+func QueueExec(b *Batch, sql string, args ...any) *BatchResult[pgconn.CommandTag] {
+	r := &BatchResult[pgconn.CommandTag]{}
+	qq := b.Queue(sql, args...)
+	qq.fn = func(br BatchResults) error {
+		return r.resolve(br.Exec())
+	}
+	qq.abandon = r.abandon
+	return r
+}
+
+// abandonQueuedQueries resolves the results of the queued queries of b starting at idx with err.
+func abandonQueuedQueries(b *Batch, idx int, err error) {
+	if b == nil || err == nil {
+		return
+	}
+
+	for _, qq := range b.queuedQueries[idx:] {
+		if qq.abandon != nil {
+			qq.abandon(err)
+		}
+	}
+}
+
 // Batch queries are a way of bundling multiple queries together to avoid
 // unnecessary network round trips. A Batch must only be sent once.
 type Batch struct {
 	queuedQueries []*QueuedQuery
+
+	// IsolateErrors causes each query to be executed in its own implicit transaction by establishing a synchronization
+	// point after each query. A failed query then does not prevent the remaining queries from being executed. Reading
+	// the results of a failed query returns its error as usual, but subsequent results can still be read. Close runs all
+	// remaining callback functions and returns BatchErrors describing every query that failed.
+	//
+	// When the batch is sent inside of an explicit transaction a failed query still aborts the transaction and all
+	// subsequent queries fail. IsolateErrors is not supported with QueryExecModeSimpleProtocol.
+	IsolateErrors bool
+
+	// MaxChunkQueries and MaxChunkBytes split the batch into chunks of at most MaxChunkQueries queries or approximately
+	// MaxChunkBytes bytes of SQL and encoded arguments. A zero value means no limit. Each chunk ends with a
+	// synchronization point. A chunk is only encoded and sent after all results of the previous chunk have been read.
+	// This bounds the memory used by very large batches and avoids the server and client blocking on each other's
+	// writes. Queries are still only sent when the results are read, but the batch has a single BatchResults.
+	//
+	// If a query fails the remaining queries of its chunk are not executed and no further chunks are sent. Unless
+	// ChunksShareTransaction is set, each chunk is run in its own implicit transaction so earlier chunks remain
+	// committed. Splitting a batch is not supported with QueryExecModeSimpleProtocol.
+	MaxChunkQueries int
+	MaxChunkBytes   int
+
+	// ChunksShareTransaction runs all chunks of a split batch in a single transaction. If the batch is not sent inside of
+	// an explicit transaction then a transaction is started before the first chunk and committed after the last chunk.
+	// If any query fails, the transaction is rolled back. ChunksShareTransaction cannot be used with IsolateErrors.
+	ChunksShareTransaction bool
+}
+
+// isChunked returns true if b is split into chunks.
+func (b *Batch) isChunked() bool {
+	return b.MaxChunkQueries > 0 || b.MaxChunkBytes > 0
+}
+
+// BatchQueryError is the error of a single query in a Batch with IsolateErrors.
+type BatchQueryError struct {
+	Index int // Position of the query in the batch.
+	SQL   string
+	Err   error
+}
+
+func (e *BatchQueryError) Error() string {
+	return fmt.Sprintf("batch query %d failed: %v", e.Index, e.Err)
+}
+
+func (e *BatchQueryError) Unwrap() error {
+	return e.Err
+}
+
+// BatchErrors is returned by BatchResults.Close for a Batch with IsolateErrors when one or more queries failed. It is
+// ordered by query position. All queries of the batch that are not included succeeded.
+type BatchErrors []*BatchQueryError
+
+func (e BatchErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d batch queries failed; first: %v", len(e), e[0])
+}
+
+func (e BatchErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
 }
 
 // Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement.
@@ -210,6 +380,8 @@ func (br *batchResults) QueryRow() Row {
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *batchResults) Close() error {
 	defer func() {
+		abandonQueuedQueries(br.b, br.qqIdx, br.err)
+
 		if !br.endTraced {
 			if br.conn != nil && br.conn.batchTracer != nil {
 				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
@@ -273,10 +445,26 @@ type pipelineBatchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+
+	// Only used when b.IsolateErrors is set.
+	lastRowsIdx  int
+	expectSync   bool
+	isolatedErrs BatchErrors
+
+	// sentIdx is the index of the first query that has not been sent yet. chunkEnd is the index of the first query after
+	// the most recently sent chunk.
+	sentIdx        int
+	chunkEnd       int
+	txStarted      bool
+	txBeginPending bool
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
 func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
+	if br.b != nil && br.b.IsolateErrors {
+		return br.execIsolated()
+	}
+
 	if br.err != nil {
 		return pgconn.CommandTag{}, br.err
 	}
@@ -286,6 +474,9 @@ func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
 	if br.lastRows != nil && br.lastRows.err != nil {
 		return pgconn.CommandTag{}, br.err
 	}
+	if err := br.advanceChunk(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
 
 	query, arguments, _ := br.nextQueryAndArgs()
 
@@ -316,6 +507,10 @@ func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
 
 // Query reads the results from the next query in the batch as if the query has been sent with Query.
 func (br *pipelineBatchResults) Query() (Rows, error) {
+	if br.b != nil && br.b.IsolateErrors {
+		return br.queryIsolated()
+	}
+
 	if br.err != nil {
 		return &baseRows{err: br.err, closed: true}, br.err
 	}
@@ -329,6 +524,9 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 		br.err = br.lastRows.err
 		return &baseRows{err: br.err, closed: true}, br.err
 	}
+	if err := br.advanceChunk(); err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
 
 	query, arguments, ok := br.nextQueryAndArgs()
 	if !ok {
@@ -378,6 +576,8 @@ func (br *pipelineBatchResults) QueryRow() Row {
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *pipelineBatchResults) Close() error {
 	defer func() {
+		abandonQueuedQueries(br.b, br.qqIdx, br.err)
+
 		if !br.endTraced {
 			if br.conn.batchTracer != nil {
 				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
@@ -386,6 +586,10 @@ func (br *pipelineBatchResults) Close() error {
 		}
 	}()
 
+	if br.b != nil && br.b.IsolateErrors {
+		return br.closeIsolated()
+	}
+
 	if br.err == nil && br.lastRows != nil && br.lastRows.err != nil {
 		br.err = br.lastRows.err
 		return br.err
@@ -414,6 +618,8 @@ func (br *pipelineBatchResults) Close() error {
 		br.err = err
 	}
 
+	br.rollbackUnfinishedTx()
+
 	return br.err
 }
 
@@ -431,3 +637,320 @@ func (br *pipelineBatchResults) nextQueryAndArgs() (query string, args []any, ok
 	}
 	return
 }
+
+// execIsolated is Exec for a batch with IsolateErrors.
+func (br *pipelineBatchResults) execIsolated() (pgconn.CommandTag, error) {
+	idx := br.qqIdx
+	rr, err := br.nextIsolatedResult()
+	if rr == nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	commandTag, err := rr.Close()
+	br.recordIsolatedError(idx, err)
+
+	if br.conn.batchTracer != nil {
+		bi := br.b.queuedQueries[idx]
+		br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
+			SQL:        bi.query,
+			Args:       bi.arguments,
+			CommandTag: commandTag,
+			Err:        err,
+		})
+	}
+
+	return commandTag, err
+}
+
+// queryIsolated is Query for a batch with IsolateErrors.
+func (br *pipelineBatchResults) queryIsolated() (Rows, error) {
+	idx := br.qqIdx
+	query := "batch query"
+	var arguments []any
+	if idx < len(br.b.queuedQueries) {
+		query = br.b.queuedQueries[idx].query
+		arguments = br.b.queuedQueries[idx].arguments
+	}
+
+	rows := br.conn.getRows(br.ctx, query, arguments)
+	rows.batchTracer = br.conn.batchTracer
+
+	rr, err := br.nextIsolatedResult()
+	if rr == nil {
+		rows.err = err
+		rows.closed = true
+		return rows, err
+	}
+
+	rows.resultReader = rr
+	br.lastRows = rows
+	br.lastRowsIdx = idx
+	return rows, nil
+}
+
+// nextIsolatedResult finishes reading the results of the previous query and returns the *pgconn.ResultReader for the
+// next query. If the next query failed without returning a ResultReader its error is recorded and returned.
+func (br *pipelineBatchResults) nextIsolatedResult() (*pgconn.ResultReader, error) {
+	if br.err != nil {
+		return nil, br.err
+	}
+	if br.closed {
+		return nil, fmt.Errorf("batch already closed")
+	}
+
+	err := br.finishIsolatedResult()
+	if err != nil {
+		return nil, err
+	}
+
+	if br.qqIdx == br.chunkEnd && br.sentIdx < len(br.b.queuedQueries) {
+		err := br.sendChunk()
+		if err != nil {
+			br.err = err
+			return nil, err
+		}
+	}
+
+	if br.qqIdx >= len(br.b.queuedQueries) {
+		return nil, errors.New("no result")
+	}
+	idx := br.qqIdx
+	bi := br.b.queuedQueries[idx]
+	br.qqIdx++
+
+	if bi.err != nil {
+		br.recordIsolatedError(idx, bi.err)
+		return nil, bi.err
+	}
+
+	results, err := br.pipeline.GetResults()
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			br.err = err
+			return nil, err
+		}
+		br.expectSync = true
+		br.recordIsolatedError(idx, err)
+		return nil, err
+	}
+
+	rr, ok := results.(*pgconn.ResultReader)
+	if !ok {
+		br.err = fmt.Errorf("unexpected pipeline result: %T", results)
+		return nil, br.err
+	}
+	br.expectSync = true
+
+	return rr, nil
+}
+
+// finishIsolatedResult closes the Rows of the previous query and reads its synchronization point.
+func (br *pipelineBatchResults) finishIsolatedResult() error {
+	if br.lastRows != nil {
+		br.lastRows.Close()
+		br.recordIsolatedError(br.lastRowsIdx, br.lastRows.err)
+		br.lastRows = nil
+	}
+
+	if br.expectSync {
+		results, err := br.pipeline.GetResults()
+		if err != nil {
+			br.err = err
+			return err
+		}
+		if _, ok := results.(*pgconn.PipelineSync); !ok {
+			br.err = fmt.Errorf("expected sync, got %T", results)
+			return br.err
+		}
+		br.expectSync = false
+	}
+
+	return nil
+}
+
+// recordIsolatedError records err as the error of the query at idx. Only the first error of each query is recorded.
+func (br *pipelineBatchResults) recordIsolatedError(idx int, err error) {
+	if err == nil {
+		return
+	}
+	if n := len(br.isolatedErrs); n > 0 && br.isolatedErrs[n-1].Index == idx {
+		return
+	}
+
+	br.isolatedErrs = append(br.isolatedErrs, &BatchQueryError{Index: idx, SQL: br.b.queuedQueries[idx].query, Err: err})
+}
+
+// closeIsolated is Close for a batch with IsolateErrors.
+func (br *pipelineBatchResults) closeIsolated() error {
+	if br.closed {
+		if br.err != nil {
+			return br.err
+		}
+		if len(br.isolatedErrs) > 0 {
+			return br.isolatedErrs
+		}
+		return nil
+	}
+
+	// Read and run fn for all remaining items. A failed query does not prevent the remaining items from being read.
+	for br.err == nil && br.qqIdx < len(br.b.queuedQueries) {
+		idx := br.qqIdx
+		if fn := br.b.queuedQueries[idx].fn; fn != nil {
+			br.recordIsolatedError(idx, fn(br))
+		} else {
+			br.Exec()
+		}
+	}
+
+	if br.err == nil {
+		br.finishIsolatedResult()
+	}
+
+	br.closed = true
+
+	err := br.pipeline.Close()
+	if br.err == nil {
+		br.err = err
+	}
+
+	if br.err != nil {
+		return br.err
+	}
+	if len(br.isolatedErrs) > 0 {
+		return br.isolatedErrs
+	}
+	return nil
+}
+
+// sendChunk encodes and sends the next chunk of queries. In a batch that is not split into chunks all queries are
+// sent at once.
+func (br *pipelineBatchResults) sendChunk() error {
+	c := br.conn
+	b := br.b
+	isolate := b.IsolateErrors
+
+	var queryCount, byteCount int
+	for br.sentIdx < len(b.queuedQueries) {
+		if queryCount > 0 && ((b.MaxChunkQueries > 0 && queryCount >= b.MaxChunkQueries) || (b.MaxChunkBytes > 0 && byteCount >= b.MaxChunkBytes)) {
+			break
+		}
+
+		bi := b.queuedQueries[br.sentIdx]
+		br.sentIdx++
+		queryCount++
+
+		if bi.err != nil {
+			continue
+		}
+
+		err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
+		if err != nil {
+			// we wrap the error so we the user can understand which query failed inside the batch
+			err = fmt.Errorf("error building query %s: %w", bi.query, err)
+			if isolate {
+				bi.err = err
+				continue
+			}
+			return err
+		}
+
+		switch {
+		case bi.sd == nil:
+			// QueryExecModeExec with IsolateErrors or chunks.
+			br.pipeline.SendQueryParams(bi.query, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		case bi.sd.Name == "":
+			br.pipeline.SendQueryParams(bi.sd.SQL, c.eqb.ParamValues, bi.sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		default:
+			br.pipeline.SendQueryPrepared(bi.sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		}
+
+		byteCount += len(bi.query)
+		for _, v := range c.eqb.ParamValues {
+			byteCount += len(v)
+		}
+
+		// With IsolateErrors every query is followed by a synchronization point so a failure only affects that query.
+		if isolate {
+			err := br.pipeline.Sync()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
+	br.chunkEnd = br.sentIdx
+
+	if br.txStarted && br.sentIdx == len(b.queuedQueries) {
+		br.pipeline.SendQueryParams("commit", nil, nil, nil, nil)
+	}
+
+	if !isolate {
+		return br.pipeline.Sync()
+	}
+
+	return nil
+}
+
+// advanceChunk prepares for reading the results of the next query in a batch that is not using IsolateErrors. If all
+// results of the current chunk have been read, the synchronization point is read and the next chunk is sent.
+func (br *pipelineBatchResults) advanceChunk() error {
+	if br.b == nil {
+		return nil
+	}
+
+	if br.txBeginPending {
+		br.txBeginPending = false
+		results, err := br.pipeline.GetResults()
+		if err != nil {
+			br.err = err
+			return err
+		}
+		if rr, ok := results.(*pgconn.ResultReader); ok {
+			_, err = rr.Close()
+		} else {
+			err = fmt.Errorf("unexpected pipeline result: %T", results)
+		}
+		if err != nil {
+			br.err = err
+			return err
+		}
+	}
+
+	if br.qqIdx < br.chunkEnd || br.sentIdx == len(br.b.queuedQueries) {
+		return nil
+	}
+
+	if br.lastRows != nil {
+		br.lastRows.Close()
+		if br.lastRows.err != nil {
+			br.err = br.lastRows.err
+			return br.err
+		}
+	}
+
+	err := readPipelineSync(br.pipeline)
+	if err == nil {
+		err = br.sendChunk()
+	}
+	if err != nil {
+		br.err = err
+		return err
+	}
+
+	return nil
+}
+
+// rollbackUnfinishedTx rolls back the transaction started for a batch with ChunksShareTransaction if it was not
+// committed because of an error.
+func (br *pipelineBatchResults) rollbackUnfinishedTx() {
+	if !br.txStarted || br.conn.pgConn.IsClosed() || br.conn.pgConn.TxStatus() == 'I' {
+		return
+	}
+
+	err := br.conn.pgConn.Exec(br.ctx, "rollback").Close()
+	if err != nil && br.err == nil {
+		br.err = err
+	}
+}
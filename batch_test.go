@@ -380,6 +380,244 @@ func TestConnSendBatchWithQueryRewriter(t *testing.T) {
 	})
 }
 
+func TestConnSendBatchIsolateErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, "create temporary table isolate_errors(id int primary key)")
+
+		batch := &pgx.Batch{IsolateErrors: true}
+		batch.Queue("insert into isolate_errors(id) values($1)", 1)
+		batch.Queue("insert into isolate_errors(id) values($1)", 1)
+		batch.Queue("insert into isolate_errors(id) values($1)", 2)
+		batch.Queue("select * from missing_table")
+		batch.Queue("select id from isolate_errors order by id")
+		batch.Queue("insert into isolate_errors(id) values($1)", 3)
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Query()
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "42P01", pgErr.Code)
+
+		rows, err := br.Query()
+		require.NoError(t, err)
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2}, ids)
+
+		err = br.Close()
+		var batchErrs pgx.BatchErrors
+		require.ErrorAs(t, err, &batchErrs)
+		require.Len(t, batchErrs, 2)
+		require.Equal(t, 1, batchErrs[0].Index)
+		require.Equal(t, 3, batchErrs[1].Index)
+		require.Equal(t, "select * from missing_table", batchErrs[1].SQL)
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from isolate_errors").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchIsolateErrorsCallbacks(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{IsolateErrors: true}
+
+		var results []int32
+		for _, divisor := range []int{1, 0, 2} {
+			batch.Queue("select 2 / $1::int", divisor).QueryRow(func(row pgx.Row) error {
+				var n int32
+				err := row.Scan(&n)
+				if err != nil {
+					return err
+				}
+				results = append(results, n)
+				return nil
+			})
+		}
+
+		err := conn.SendBatch(ctx, batch).Close()
+		var batchErrs pgx.BatchErrors
+		require.ErrorAs(t, err, &batchErrs)
+		require.Len(t, batchErrs, 1)
+		require.Equal(t, 1, batchErrs[0].Index)
+		require.Equal(t, []int32{2, 1}, results)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchIsolateErrorsSimpleProtocol(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{IsolateErrors: true}
+		batch.Queue("select 1")
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.ErrorContains(t, err, "IsolateErrors")
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchChunks(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, batch := range []*pgx.Batch{{MaxChunkQueries: 7}, {MaxChunkBytes: 64}} {
+			var sum int64
+			for i := 0; i < 100; i++ {
+				batch.Queue("select $1::int8", i).QueryRow(func(row pgx.Row) error {
+					var n int64
+					err := row.Scan(&n)
+					sum += n
+					return err
+				})
+			}
+
+			br := conn.SendBatch(ctx, batch)
+			var n int64
+			err := br.QueryRow().Scan(&n)
+			require.NoError(t, err)
+			require.EqualValues(t, 0, n)
+			sum += n
+
+			err = br.Close()
+			require.NoError(t, err)
+			require.EqualValues(t, 4950, sum)
+
+			ensureConnValid(t, conn)
+		}
+	})
+}
+
+func TestConnSendBatchChunksError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, shareTx := range []bool{false, true} {
+			mustExec(t, conn, "create temporary table chunks(id int primary key)")
+
+			batch := &pgx.Batch{MaxChunkQueries: 3, ChunksShareTransaction: shareTx}
+			for _, id := range []int{1, 2, 3, 4, 5, 5, 6, 7, 8, 9} {
+				batch.Queue("insert into chunks(id) values($1)", id)
+			}
+
+			err := conn.SendBatch(ctx, batch).Close()
+			var pgErr *pgconn.PgError
+			require.ErrorAs(t, err, &pgErr)
+			require.Equal(t, "23505", pgErr.Code)
+			require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+			var n int64
+			err = conn.QueryRow(ctx, "select count(*) from chunks").Scan(&n)
+			require.NoError(t, err)
+			if shareTx {
+				require.EqualValues(t, 0, n)
+			} else {
+				// Only the first chunk is committed.
+				require.EqualValues(t, 3, n)
+			}
+
+			mustExec(t, conn, "drop table chunks")
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchChunksShareTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeExec}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{MaxChunkQueries: 2, ChunksShareTransaction: true}
+		for i := 0; i < 5; i++ {
+			batch.Queue("select txid_current()")
+		}
+
+		br := conn.SendBatch(ctx, batch)
+		var txids []int64
+		for i := 0; i < 5; i++ {
+			var txid int64
+			err := br.QueryRow().Scan(&txid)
+			require.NoError(t, err)
+			txids = append(txids, txid)
+		}
+		require.NoError(t, br.Close())
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		for _, txid := range txids {
+			require.Equal(t, txids[0], txid)
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
 // https://github.com/jackc/pgx/issues/856
 func TestConnSendBatchWithPreparedStatementAndStatementCacheDisabled(t *testing.T) {
 	t.Parallel()
@@ -1027,3 +1265,80 @@ func ExampleConn_SendBatch() {
 	// 3
 	// 5
 }
+
+func TestConnSendBatchQueueCollect(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		numbers := pgx.QueueCollect(batch, pgx.RowTo[int32], "select n from generate_series(1, $1::int) n", 3)
+		insert := pgx.QueueExec(batch, "select 1")
+		first := pgx.QueueCollectOneRow(batch, pgx.RowTo[string], "select n::text from generate_series(4, 5) n")
+		only := pgx.QueueCollectExactlyOneRow(batch, pgx.RowTo[int64], "select 42::bigint")
+
+		_, err := numbers.Get()
+		require.ErrorIs(t, err, pgx.ErrBatchResultNotResolved)
+		require.False(t, numbers.Resolved())
+
+		err = conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		// Results can be read in any order once the batch is closed.
+		n, err := only.Get()
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, n)
+
+		s, err := first.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "4", s)
+
+		ct, err := insert.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1", ct.String())
+
+		require.True(t, numbers.Resolved())
+		ns, err := numbers.Get()
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3}, ns)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueueCollectError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		ok := pgx.QueueCollect(batch, pgx.RowTo[int32], "select 1")
+		failed := pgx.QueueCollectExactlyOneRow(batch, pgx.RowTo[int32], "select 1/(1-$1::int)", 1)
+		skipped := pgx.QueueExec(batch, "select 3")
+
+		err := conn.SendBatch(ctx, batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+
+		ns, err := ok.Get()
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1}, ns)
+
+		_, err = failed.Get()
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+
+		// A query that was not run because of an earlier error is resolved with the batch error.
+		require.True(t, skipped.Resolved())
+		_, err = skipped.Get()
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/internal/anynil"
+	"github.com/jackc/pgx/v5/internal/querytrace"
 	"github.com/jackc/pgx/v5/internal/sanitize"
 	"github.com/jackc/pgx/v5/internal/stmtcache"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -35,12 +36,47 @@ type ConnConfig struct {
 	// "cache_describe" query exec mode.
 	DescriptionCacheCapacity int
 
+	// DescriptionCache is the description cache used when executing a query with "cache_describe" query exec mode. If
+	// set, DescriptionCacheCapacity is ignored and the cache may be shared with other connections. If nil, each
+	// connection has its own description cache.
+	DescriptionCache *SharedDescriptionCache
+
+	// QueryRewriters are applied in order to the SQL and arguments of every query executed with Exec, Query, QueryRow,
+	// QueryMulti, SendBatch, and Pipeline.SendQuery. They are applied after a QueryRewriter passed as the first argument
+	// to the query, such as NamedArgs, so they receive positional arguments. They are not applied when the SQL is the
+	// name of a prepared statement. The arguments they return must not include query options such as QueryExecMode. The
+	// QueryExecMode of the query can be read from the context passed to them with QueryExecModeFromContext.
+	QueryRewriters []QueryRewriter
+
+	// BuildStatementCache builds the statement cache of each connection. If set, StatementCacheCapacity is ignored. It
+	// can be used to configure the cache with NewStatementCache or to provide a custom StatementCache. If it returns
+	// nil, the statement cache is disabled.
+	BuildStatementCache BuildStatementCacheFunc
+
+	// BuildDescriptionCache builds the description cache of each connection. If set, DescriptionCacheCapacity is
+	// ignored. DescriptionCache takes precedence over BuildDescriptionCache. If it returns nil, the description cache is
+	// disabled.
+	BuildDescriptionCache BuildStatementCacheFunc
+
 	// DefaultQueryExecMode controls the default mode for executing queries. By default pgx uses the extended protocol
 	// and automatically prepares and caches prepared statements. However, this may be incompatible with proxies such as
 	// PGBouncer. In this case it may be preferable to use QueryExecModeExec or QueryExecModeSimpleProtocol. The same
 	// functionality can be controlled on a per query basis by passing a QueryExecMode as the first query argument.
 	DefaultQueryExecMode QueryExecMode
 
+	// CopyFromEncodeWorkers is the number of goroutines used by CopyFrom to encode rows. The rows are still read from the
+	// CopyFromSource on a single goroutine and sent to the server in order. Encoding in parallel can increase the
+	// throughput of CopyFrom when encoding values such as numerics, JSON, and arrays is the bottleneck. If less than 2,
+	// rows are encoded on the goroutine that sends them. Values returned by the CopyFromSource must not be modified
+	// after they are returned when encoding in parallel.
+	CopyFromEncodeWorkers int
+
+	// SchemaChangeChannel is the channel the connection listens on for schema change notifications. When a notification
+	// is received on it the statement cache, the description cache and the function OIDs cached by FunctionCall are
+	// invalidated. See InstallSchemaChangeNotifier. Notifications on this channel are not passed to Config.OnNotification
+	// or WaitForNotification. If empty, the connection does not listen for schema changes.
+	SchemaChangeChannel string
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -71,11 +107,15 @@ type Conn struct {
 	statementCache     stmtcache.Cache
 	descriptionCache   stmtcache.Cache
 
+	functionDescriptions map[string]*functionDescription
+
 	queryTracer    QueryTracer
 	batchTracer    BatchTracer
 	copyFromTracer CopyFromTracer
 	prepareTracer  PrepareTracer
 
+	queryRetryTracer QueryRetryTracer
+
 	notifications []*pgconn.Notification
 
 	doneChan   chan struct{}
@@ -188,12 +228,16 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 		}
 	}
 
+	schemaChangeChannel := config.RuntimeParams["schema_change_channel"]
+	delete(config.RuntimeParams, "schema_change_channel")
+
 	connConfig := &ConnConfig{
 		Config:                   *config,
 		createdByParseConfig:     true,
 		StatementCacheCapacity:   statementCacheCapacity,
 		DescriptionCacheCapacity: descriptionCacheCapacity,
 		DefaultQueryExecMode:     defaultQueryExecMode,
+		SchemaChangeChannel:      schemaChangeChannel,
 		connString:               connString,
 	}
 
@@ -214,6 +258,10 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 //   - description_cache_capacity.
 //     The maximum size of the description cache used when executing a query with "cache_describe" query exec mode.
 //     Default: 512.
+//
+//   - schema_change_channel.
+//     The channel the connection listens on for schema change notifications. See ConnConfig.SchemaChangeChannel.
+//     Default: "" (disabled).
 func ParseConfig(connString string) (*ConnConfig, error) {
 	return ParseConfigWithOptions(connString, ParseConfigOptions{})
 }
@@ -248,12 +296,26 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	if t, ok := c.queryTracer.(PrepareTracer); ok {
 		c.prepareTracer = t
 	}
+	if t, ok := c.queryTracer.(QueryRetryTracer); ok {
+		c.queryRetryTracer = t
+	}
 
 	// Only install pgx notification system if no other callback handler is present.
 	if config.Config.OnNotification == nil {
 		config.Config.OnNotification = c.bufferNotifications
 	}
 
+	if config.SchemaChangeChannel != "" {
+		onNotification := config.Config.OnNotification
+		config.Config.OnNotification = func(pgConn *pgconn.PgConn, n *pgconn.Notification) {
+			if n.Channel == config.SchemaChangeChannel {
+				c.invalidateCaches()
+				return
+			}
+			onNotification(pgConn, n)
+		}
+	}
+
 	c.pgConn, err = pgconn.ConnectConfig(ctx, &config.Config)
 	if err != nil {
 		return nil, err
@@ -264,12 +326,15 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	c.closedChan = make(chan error)
 	c.wbuf = make([]byte, 0, 1024)
 
-	if c.config.StatementCacheCapacity > 0 {
-		c.statementCache = stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
-	}
+	c.statementCache = c.buildStatementCache()
+	c.descriptionCache = c.buildDescriptionCache()
 
-	if c.config.DescriptionCacheCapacity > 0 {
-		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
+	if c.config.SchemaChangeChannel != "" {
+		_, err = c.pgConn.Exec(ctx, "listen "+quoteIdentifier(c.config.SchemaChangeChannel)).ReadAll()
+		if err != nil {
+			c.pgConn.Close(ctx)
+			return nil, fmt.Errorf("failed to listen for schema changes: %w", err)
+		}
 	}
 
 	return c, nil
@@ -360,19 +425,52 @@ func (c *Conn) Deallocate(ctx context.Context, name string) error {
 	return nil
 }
 
-// DeallocateAll releases all previously prepared statements from the server and client, where it also resets the statement and description cache.
+// DeallocateAll releases all previously prepared statements from the server and client, where it also resets the statement and description cache
+// and the function OIDs cached by FunctionCall.
 func (c *Conn) DeallocateAll(ctx context.Context) error {
 	c.preparedStatements = map[string]*pgconn.StatementDescription{}
-	if c.config.StatementCacheCapacity > 0 {
-		c.statementCache = stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
+	if c.statementCache != nil {
+		c.statementCache.InvalidateAll()
+		c.statementCache.HandleInvalidated()
 	}
-	if c.config.DescriptionCacheCapacity > 0 {
-		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
+	// Descriptions do not depend on prepared statements so a shared description cache is kept.
+	if c.descriptionCache != nil && c.config.DescriptionCache == nil {
+		c.descriptionCache.InvalidateAll()
+		c.descriptionCache.HandleInvalidated()
 	}
+	c.functionDescriptions = nil
 	_, err := c.pgConn.Exec(ctx, "deallocate all").ReadAll()
 	return err
 }
 
+func (c *Conn) buildStatementCache() stmtcache.Cache {
+	if c.config.BuildStatementCache != nil {
+		return c.config.BuildStatementCache(c)
+	}
+
+	if c.config.StatementCacheCapacity > 0 {
+		return stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
+	}
+
+	return nil
+}
+
+func (c *Conn) buildDescriptionCache() stmtcache.Cache {
+	if c.config.DescriptionCache != nil {
+		return c.config.DescriptionCache
+	}
+
+	if c.config.BuildDescriptionCache != nil {
+		return c.config.BuildDescriptionCache(c)
+	}
+
+	if c.config.DescriptionCacheCapacity > 0 {
+		return stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
+	}
+
+	return nil
+}
+
 func (c *Conn) bufferNotifications(_ *pgconn.PgConn, n *pgconn.Notification) {
 	c.notifications = append(c.notifications, n)
 }
@@ -479,6 +577,15 @@ optionLoop:
 		}
 	}
 
+	rewriteMode := mode
+	if len(arguments) == 0 {
+		rewriteMode = QueryExecModeSimpleProtocol
+	}
+	sql, arguments, err = c.applyQueryRewriters(ctx, rewriteMode, sql, arguments)
+	if err != nil {
+		return pgconn.CommandTag{}, fmt.Errorf("rewrite query failed: %w", err)
+	}
+
 	// Always use simple protocol when there are no arguments.
 	if len(arguments) == 0 {
 		mode = QueryExecModeSimpleProtocol
@@ -494,6 +601,7 @@ optionLoop:
 			return pgconn.CommandTag{}, errDisabledStatementCache
 		}
 		sd := c.statementCache.Get(sql)
+		cached := sd != nil
 		if sd == nil {
 			sd, err = c.Prepare(ctx, stmtcache.StatementName(sql), sql)
 			if err != nil {
@@ -502,7 +610,18 @@ optionLoop:
 			c.statementCache.Put(sd)
 		}
 
-		return c.execPrepared(ctx, sd, arguments)
+		commandTag, err = c.execPrepared(ctx, sd, arguments)
+		if cached && err != nil && c.retryInvalidCachedStatement(ctx, sql, err) {
+			sd, err = c.Prepare(ctx, stmtcache.StatementName(sql), sql)
+			if err != nil {
+				return pgconn.CommandTag{}, err
+			}
+			c.statementCache.Put(sd)
+
+			commandTag, err = c.execPrepared(ctx, sd, arguments)
+		}
+
+		return commandTag, err
 	case QueryExecModeCacheDescribe:
 		if c.descriptionCache == nil {
 			return pgconn.CommandTag{}, errDisabledDescriptionCache
@@ -516,7 +635,12 @@ optionLoop:
 			c.descriptionCache.Put(sd)
 		}
 
-		return c.execParams(ctx, sd, arguments)
+		commandTag, err = c.execParams(ctx, sd, arguments)
+		if isSchemaChangeError(err) {
+			c.descriptionCache.Invalidate(sql)
+		}
+
+		return commandTag, err
 	case QueryExecModeDescribeExec:
 		sd, err := c.Prepare(ctx, "", sql)
 		if err != nil {
@@ -673,11 +797,60 @@ type QueryResultFormats []int16
 // QueryResultFormatsByOID controls the result format (text=0, binary=1) of a query by the result column OID.
 type QueryResultFormatsByOID map[uint32]int16
 
-// QueryRewriter rewrites a query when used as the first arguments to a query method.
+// QueryFetchSize limits the number of rows fetched from the server at a time by Query when used as one of the first
+// arguments. When the rows already fetched have been read the next page is requested. This allows streaming very large
+// results with bounded memory without declaring a cursor. It is not supported with QueryExecModeSimpleProtocol. 0
+// fetches all rows at once.
+type QueryFetchSize uint32
+
+// QueryRewriter rewrites a query when used as the first arguments to a query method or when set in
+// ConnConfig.QueryRewriters.
 type QueryRewriter interface {
 	RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
 }
 
+// QueryRewriterFunc is a function that implements QueryRewriter.
+type QueryRewriterFunc func(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
+
+// RewriteQuery implements the QueryRewriter interface.
+func (f QueryRewriterFunc) RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
+	return f(ctx, conn, sql, args)
+}
+
+type queryExecModeCtxKey struct{}
+
+// QueryExecModeFromContext returns the QueryExecMode a query is executed with when called from a QueryRewriter in
+// ConnConfig.QueryRewriters with the context passed to RewriteQuery. ok is false for other contexts.
+func QueryExecModeFromContext(ctx context.Context) (mode QueryExecMode, ok bool) {
+	mode, ok = ctx.Value(queryExecModeCtxKey{}).(QueryExecMode)
+	return mode, ok
+}
+
+// applyQueryRewriters applies ConnConfig.QueryRewriters to sql and args in order. mode is the QueryExecMode the query
+// is executed with. sql is not rewritten if it is the name of a prepared statement or if ctx skips the rewriters. The
+// resulting sql and args are recorded for the tracers of this module.
+func (c *Conn) applyQueryRewriters(ctx context.Context, mode QueryExecMode, sql string, args []any) (string, []any, error) {
+	if len(c.config.QueryRewriters) > 0 && !querytrace.SkipQueryRewriters(ctx) {
+		if _, ok := c.preparedStatements[sql]; !ok {
+			rewriteCtx := context.WithValue(ctx, queryExecModeCtxKey{}, mode)
+			for _, queryRewriter := range c.config.QueryRewriters {
+				var err error
+				sql, args, err = queryRewriter.RewriteQuery(rewriteCtx, c, sql, args)
+				if err != nil {
+					return "", nil, err
+				}
+			}
+		}
+	}
+
+	if q := querytrace.SentQuery(ctx); q != nil {
+		q.SQL = sql
+		q.Args = args
+	}
+
+	return sql, args, nil
+}
+
 // Query sends a query to the server and returns a Rows to read the results. Only errors encountered sending the query
 // and initializing Rows will be returned. Err() on the returned Rows must be checked after the Rows is closed to
 // determine if the query executed successfully.
@@ -696,9 +869,9 @@ type QueryRewriter interface {
 // An implementor of QueryRewriter may be passed as the first element of args. It can rewrite the sql and change or
 // replace args. For example, NamedArgs is QueryRewriter that implements named arguments.
 //
-// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats, and
-// QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
-// needed. See the documentation for those types for details.
+// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats,
+// QueryResultFormatsByOID, and QueryFetchSize may be used as the first args to control exactly how the query is
+// executed. This is rarely needed. See the documentation for those types for details.
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
 	if c.queryTracer != nil {
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
@@ -713,6 +886,7 @@ func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
 
 	var resultFormats QueryResultFormats
 	var resultFormatsByOID QueryResultFormatsByOID
+	var fetchSize QueryFetchSize
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
 
@@ -725,6 +899,9 @@ optionLoop:
 		case QueryResultFormatsByOID:
 			resultFormatsByOID = arg
 			args = args[1:]
+		case QueryFetchSize:
+			fetchSize = arg
+			args = args[1:]
 		case QueryExecMode:
 			mode = arg
 			args = args[1:]
@@ -736,11 +913,16 @@ optionLoop:
 		}
 	}
 
-	if queryRewriter != nil {
+	if queryRewriter != nil || len(c.config.QueryRewriters) > 0 {
 		var err error
 		originalSQL := sql
 		originalArgs := args
-		sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args)
+		if queryRewriter != nil {
+			sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args)
+		}
+		if err == nil {
+			sql, args, err = c.applyQueryRewriters(ctx, mode, sql, args)
+		}
 		if err != nil {
 			rows := c.getRows(ctx, originalSQL, originalArgs)
 			err = fmt.Errorf("rewrite query failed: %w", err)
@@ -761,42 +943,56 @@ optionLoop:
 	var err error
 	sd, explicitPreparedStatement := c.preparedStatements[sql]
 	if sd != nil || mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe || mode == QueryExecModeDescribeExec {
-		if sd == nil {
-			sd, err = c.getStatementDescription(ctx, mode, sql)
-			if err != nil {
-				rows.fatal(err)
-				return rows, err
+		for retried := false; ; retried = true {
+			if sd == nil {
+				sd, err = c.getStatementDescription(ctx, mode, sql)
+				if err != nil {
+					rows.fatal(err)
+					return rows, err
+				}
 			}
-		}
 
-		if len(sd.ParamOIDs) != len(args) {
-			rows.fatal(fmt.Errorf("expected %d arguments, got %d", len(sd.ParamOIDs), len(args)))
-			return rows, rows.err
-		}
+			if len(sd.ParamOIDs) != len(args) {
+				rows.fatal(fmt.Errorf("expected %d arguments, got %d", len(sd.ParamOIDs), len(args)))
+				return rows, rows.err
+			}
 
-		rows.sql = sd.SQL
+			rows.sql = sd.SQL
 
-		err = c.eqb.Build(c.typeMap, sd, args)
-		if err != nil {
-			rows.fatal(err)
-			return rows, rows.err
-		}
+			err = c.eqb.Build(c.typeMap, sd, args)
+			if err != nil {
+				rows.fatal(err)
+				return rows, rows.err
+			}
 
-		if resultFormatsByOID != nil {
-			resultFormats = make([]int16, len(sd.Fields))
-			for i := range resultFormats {
-				resultFormats[i] = resultFormatsByOID[uint32(sd.Fields[i].DataTypeOID)]
+			formats := resultFormats
+			if resultFormatsByOID != nil {
+				formats = make([]int16, len(sd.Fields))
+				for i := range formats {
+					formats[i] = resultFormatsByOID[uint32(sd.Fields[i].DataTypeOID)]
+				}
 			}
-		}
 
-		if resultFormats == nil {
-			resultFormats = c.eqb.ResultFormats
-		}
+			if formats == nil {
+				formats = c.eqb.ResultFormats
+			}
 
-		if !explicitPreparedStatement && mode == QueryExecModeCacheDescribe {
-			rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats)
-		} else {
-			rows.resultReader = c.pgConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats)
+			if !explicitPreparedStatement && mode == QueryExecModeCacheDescribe {
+				rows.resultReader = c.pgConn.ExecParamsPortal(ctx, "", sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, formats, uint32(fetchSize))
+			} else {
+				rows.resultReader = c.pgConn.ExecPreparedPortal(ctx, "", sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, formats, uint32(fetchSize))
+			}
+
+			// A statement that returns rows and fails before its row description is received may have failed because its
+			// cached plan became invalid.
+			if retried || explicitPreparedStatement || mode != QueryExecModeCacheStatement || len(sd.Fields) == 0 || rows.resultReader.FieldDescriptions() != nil {
+				break
+			}
+			_, err = rows.resultReader.Close()
+			if !c.retryInvalidCachedStatement(ctx, sql, err) {
+				break
+			}
+			sd = nil
 		}
 	} else if mode == QueryExecModeExec {
 		err := c.eqb.Build(c.typeMap, nil, args)
@@ -805,8 +1001,14 @@ optionLoop:
 			return rows, rows.err
 		}
 
-		rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		rows.resultReader = c.pgConn.ExecParamsPortal(ctx, "", sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats, uint32(fetchSize))
 	} else if mode == QueryExecModeSimpleProtocol {
+		if fetchSize > 0 {
+			err = errors.New("QueryFetchSize is not supported with QueryExecModeSimpleProtocol")
+			rows.fatal(err)
+			return rows, err
+		}
+
 		sql, err = c.sanitizeForSimpleQuery(sql, args...)
 		if err != nil {
 			rows.fatal(err)
@@ -899,6 +1101,12 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 		}()
 	}
 
+	defer func() {
+		if err := br.(interface{ earlyError() error }).earlyError(); err != nil {
+			abandonQueuedQueries(b, 0, err)
+		}
+	}()
+
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
 		return &batchResults{ctx: ctx, conn: c, err: err}
 	}
@@ -928,6 +1136,12 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 			}
 		}
 
+		var err error
+		sql, arguments, err = c.applyQueryRewriters(ctx, c.config.DefaultQueryExecMode, sql, arguments)
+		if err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("rewrite query failed: %w", err)}
+		}
+
 		bi.query = sql
 		bi.arguments = arguments
 	}
@@ -935,9 +1149,19 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 	// TODO: changing mode per batch? Update Batch.Queue function comment when implemented
 	mode := c.config.DefaultQueryExecMode
 	if mode == QueryExecModeSimpleProtocol {
+		if b.IsolateErrors {
+			return &batchResults{ctx: ctx, conn: c, err: errors.New("IsolateErrors is not supported with QueryExecModeSimpleProtocol")}
+		}
+		if b.isChunked() {
+			return &batchResults{ctx: ctx, conn: c, err: errors.New("splitting a batch into chunks is not supported with QueryExecModeSimpleProtocol")}
+		}
 		return c.sendBatchQueryExecModeSimpleProtocol(ctx, b)
 	}
 
+	if b.IsolateErrors && b.ChunksShareTransaction {
+		return &batchResults{ctx: ctx, conn: c, err: errors.New("IsolateErrors cannot be used with ChunksShareTransaction")}
+	}
+
 	// All other modes use extended protocol and thus can use prepared statements.
 	for _, bi := range b.queuedQueries {
 		if sd, ok := c.preparedStatements[bi.query]; ok {
@@ -947,6 +1171,9 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 
 	switch mode {
 	case QueryExecModeExec:
+		if b.IsolateErrors || b.isChunked() {
+			return c.sendBatchExtendedWithDescription(ctx, b, nil, nil)
+		}
 		return c.sendBatchQueryExecModeExec(ctx, b)
 	case QueryExecModeCacheStatement:
 		return c.sendBatchQueryExecModeCacheStatement(ctx, b)
@@ -1108,78 +1335,116 @@ func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, d
 		}
 	}()
 
+	// With IsolateErrors every request is followed by a synchronization point so a failure only affects that request.
+	isolate := b.IsolateErrors
+	var prepareErrs map[*pgconn.StatementDescription]error
+
 	// Prepare any needed queries
 	if len(distinctNewQueries) > 0 {
 		for _, sd := range distinctNewQueries {
 			pipeline.SendPrepare(sd.Name, sd.SQL, nil)
+			if isolate {
+				err := pipeline.Sync()
+				if err != nil {
+					return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+				}
+			}
 		}
 
-		err := pipeline.Sync()
-		if err != nil {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+		if !isolate {
+			err := pipeline.Sync()
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+			}
 		}
 
 		for _, sd := range distinctNewQueries {
 			results, err := pipeline.GetResults()
 			if err != nil {
-				return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
-			}
+				var pgErr *pgconn.PgError
+				if !isolate || !errors.As(err, &pgErr) {
+					return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+				}
 
-			resultSD, ok := results.(*pgconn.StatementDescription)
-			if !ok {
-				return &pipelineBatchResults{ctx: ctx, conn: c, err: fmt.Errorf("expected statement description, got %T", results), closed: true}
-			}
+				if prepareErrs == nil {
+					prepareErrs = make(map[*pgconn.StatementDescription]error)
+				}
+				prepareErrs[sd] = err
+			} else {
+				resultSD, ok := results.(*pgconn.StatementDescription)
+				if !ok {
+					return &pipelineBatchResults{ctx: ctx, conn: c, err: fmt.Errorf("expected statement description, got %T", results), closed: true}
+				}
 
-			// Fill in the previously empty / pending statement descriptions.
-			sd.ParamOIDs = resultSD.ParamOIDs
-			sd.Fields = resultSD.Fields
-		}
+				// Fill in the previously empty / pending statement descriptions.
+				sd.ParamOIDs = resultSD.ParamOIDs
+				sd.Fields = resultSD.Fields
+			}
 
-		results, err := pipeline.GetResults()
-		if err != nil {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+			if isolate {
+				err = readPipelineSync(pipeline)
+				if err != nil {
+					return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+				}
+			}
 		}
 
-		_, ok := results.(*pgconn.PipelineSync)
-		if !ok {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: fmt.Errorf("expected sync, got %T", results), closed: true}
+		if !isolate {
+			err := readPipelineSync(pipeline)
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+			}
 		}
 	}
 
 	// Put all statements into the cache. It's fine if it overflows because HandleInvalidated will clean them up later.
 	if sdCache != nil {
 		for _, sd := range distinctNewQueries {
-			sdCache.Put(sd)
+			if _, failed := prepareErrs[sd]; !failed {
+				sdCache.Put(sd)
+			}
 		}
 	}
 
-	// Queue the queries.
 	for _, bi := range b.queuedQueries {
-		err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
-		if err != nil {
-			// we wrap the error so we the user can understand which query failed inside the batch
-			err = fmt.Errorf("error building query %s: %w", bi.query, err)
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
+		if err, failed := prepareErrs[bi.sd]; failed {
+			bi.err = err
 		}
+	}
 
-		if bi.sd.Name == "" {
-			pipeline.SendQueryParams(bi.sd.SQL, c.eqb.ParamValues, bi.sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats)
-		} else {
-			pipeline.SendQueryPrepared(bi.sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
-		}
+	pbr = &pipelineBatchResults{
+		ctx:      ctx,
+		conn:     c,
+		pipeline: pipeline,
+		b:        b,
 	}
 
-	err := pipeline.Sync()
+	if b.isChunked() && b.ChunksShareTransaction && c.pgConn.TxStatus() == 'I' {
+		pipeline.SendQueryParams("begin", nil, nil, nil, nil)
+		pbr.txStarted = true
+		pbr.txBeginPending = true
+	}
+
+	err := pbr.sendChunk()
 	if err != nil {
 		return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
 	}
 
-	return &pipelineBatchResults{
-		ctx:      ctx,
-		conn:     c,
-		pipeline: pipeline,
-		b:        b,
+	return pbr
+}
+
+// readPipelineSync reads the next result of pipeline and ensures it is a synchronization point.
+func readPipelineSync(pipeline *pgconn.Pipeline) error {
+	results, err := pipeline.GetResults()
+	if err != nil {
+		return err
 	}
+
+	if _, ok := results.(*pgconn.PipelineSync); !ok {
+		return fmt.Errorf("expected sync, got %T", results)
+	}
+
+	return nil
 }
 
 func (c *Conn) sanitizeForSimpleQuery(sql string, args ...any) (string, error) {
@@ -1345,6 +1610,56 @@ order by attnum`,
 	return fields, nil
 }
 
+// isInvalidCachedPlanError returns true if err is a feature_not_supported error. The server returns it when a
+// prepared statement is executed after a schema change changed its result type (e.g. "cached plan must not change
+// result type"). The SQLSTATE is checked instead of the message as the message may be translated.
+func isInvalidCachedPlanError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "0A000"
+}
+
+// isSchemaChangeError reports whether err is an error returned by the server that may be caused by executing a query
+// with a statement description that is out of date because the schema has changed.
+func isSchemaChangeError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case "0A000", // feature_not_supported, e.g. cached plan must not change result type
+		"08P01", // protocol_violation, e.g. the number of result formats does not match the columns
+		"42P01", // undefined_table
+		"42703", // undefined_column
+		"42804", // datatype_mismatch
+		"42883": // undefined_function
+		return true
+	}
+
+	return false
+}
+
+// retryInvalidCachedStatement is called when executing the cached prepared statement for sql failed with err. If err
+// is an invalid cached plan error and the connection is not in a transaction, the statement is removed from the
+// statement cache and deallocated and true is returned. The caller may then prepare and execute the statement once
+// more. Inside a transaction the failed statement has already aborted the transaction so it cannot be retried.
+func (c *Conn) retryInvalidCachedStatement(ctx context.Context, sql string, err error) bool {
+	if c.statementCache == nil || !isInvalidCachedPlanError(err) || c.pgConn.TxStatus() != 'I' {
+		return false
+	}
+
+	c.statementCache.Invalidate(sql)
+	if c.deallocateInvalidatedCachedStatements(ctx) != nil {
+		return false
+	}
+
+	if c.queryRetryTracer != nil {
+		c.queryRetryTracer.TraceQueryRetry(ctx, c, TraceQueryRetryData{SQL: sql, Err: err})
+	}
+
+	return true
+}
+
 func (c *Conn) deallocateInvalidatedCachedStatements(ctx context.Context) error {
 	if c.pgConn.TxStatus() != 'I' {
 		return nil
@@ -152,6 +152,11 @@ func TestParseConfigExtractsStatementCacheOptions(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 42, config.DescriptionCacheCapacity)
 
+	config, err = pgx.ParseConfig("schema_change_channel=schema_changes")
+	require.NoError(t, err)
+	require.Equal(t, "schema_changes", config.SchemaChangeChannel)
+	require.NotContains(t, config.RuntimeParams, "schema_change_channel")
+
 	//	default_query_exec_mode
 	//		Possible values: "cache_statement", "cache_describe", "describe_exec", "exec", and "simple_protocol". See
 
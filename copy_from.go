@@ -5,9 +5,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgcopy"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // CopyFromRows returns a CopyFromSource interface over the provided rows slice
@@ -91,6 +96,138 @@ func (g *copyFromFunc) Err() error {
 	return g.err
 }
 
+// CopyFromStructs returns a *StructCopyFromSource over rows making it usable by *Conn.CopyFrom. T must be a struct or a
+// pointer to a struct. The columns and their order are derived from the public fields of T. As with RowToStructByName,
+// the column name can be set with a "db" struct tag, fields with a "db" struct tag of "-" are ignored, and the fields
+// of embedded structs are included. Without a "db" struct tag the column name is the lower-cased field name (e.g.
+// FirstName becomes firstname), so a struct used with CopyFromStructs can be read back with RowToStructByName. CopyFrom
+// needs the exact column names, so use a "db" struct tag for columns such as first_name. Use ColumnNames to get the
+// column names to pass to CopyFrom.
+//
+// The fields of T are inspected once and the result is cached, so no type inspection is done per row.
+func CopyFromStructs[T any](rows []T) *StructCopyFromSource[T] {
+	var zero T
+	plan, err := lookupStructCopyPlan(reflect.TypeOf(&zero).Elem())
+	return &StructCopyFromSource[T]{rows: rows, idx: -1, plan: plan, err: err}
+}
+
+// StructCopyFromSource is a CopyFromSource over a slice of structs. It is created by CopyFromStructs. The slice returned
+// by Values is reused for the next row.
+type StructCopyFromSource[T any] struct {
+	rows   []T
+	idx    int
+	plan   *structCopyPlan
+	values []any
+	err    error
+}
+
+// ColumnNames returns the column names derived from the fields of T in the order the values are returned by Values.
+func (s *StructCopyFromSource[T]) ColumnNames() []string {
+	if s.plan == nil {
+		return nil
+	}
+	return s.plan.columnNames
+}
+
+func (s *StructCopyFromSource[T]) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	s.idx++
+	return s.idx < len(s.rows)
+}
+
+func (s *StructCopyFromSource[T]) Values() ([]any, error) {
+	v := reflect.ValueOf(&s.rows[s.idx]).Elem()
+	if s.plan.ptr {
+		if v.IsNil() {
+			s.err = fmt.Errorf("row %d is nil", s.idx)
+			return nil, s.err
+		}
+		v = v.Elem()
+	}
+
+	// CopyFrom does not retain the values of a row after the next call to Values so the slice is reused.
+	if s.values == nil {
+		s.values = make([]any, len(s.plan.fieldIndexes))
+	}
+	for i, fieldIndex := range s.plan.fieldIndexes {
+		s.values[i] = v.FieldByIndex(fieldIndex).Interface()
+	}
+
+	return s.values, nil
+}
+
+func (s *StructCopyFromSource[T]) Err() error {
+	return s.err
+}
+
+// structCopyPlan is the column names and field indexes of a struct type used by StructCopyFromSource.
+type structCopyPlan struct {
+	ptr          bool
+	columnNames  []string
+	fieldIndexes [][]int
+}
+
+var structCopyPlans sync.Map // map[reflect.Type]*structCopyPlan
+
+func lookupStructCopyPlan(t reflect.Type) (*structCopyPlan, error) {
+	if plan, ok := structCopyPlans.Load(t); ok {
+		return plan.(*structCopyPlan), nil
+	}
+
+	plan := &structCopyPlan{}
+	structType := t
+	if structType.Kind() == reflect.Ptr {
+		plan.ptr = true
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct or a pointer to a struct", t)
+	}
+
+	plan.appendFields(structType, nil)
+	if len(plan.columnNames) == 0 {
+		return nil, fmt.Errorf("%v has no public fields", t)
+	}
+
+	structCopyPlans.Store(t, plan)
+	return plan, nil
+}
+
+func (plan *structCopyPlan) appendFields(t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		// Handle anonymous struct embedding, but do not try to handle embedded pointers.
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			plan.appendFields(sf.Type, fieldIndex)
+			continue
+		}
+		if sf.PkgPath != "" {
+			// Field is unexported, skip it.
+			continue
+		}
+
+		dbTag, dbTagPresent := sf.Tag.Lookup(structTagKey)
+		if dbTagPresent {
+			dbTag, _, _ = strings.Cut(dbTag, ",")
+		}
+		if dbTag == "-" {
+			// Field is ignored, skip it.
+			continue
+		}
+		colName := dbTag
+		if !dbTagPresent {
+			colName = strings.ToLower(sf.Name)
+		}
+
+		plan.columnNames = append(plan.columnNames, colName)
+		plan.fieldIndexes = append(plan.fieldIndexes, fieldIndex)
+	}
+}
+
 // CopyFromSource is the interface used by *Conn.CopyFrom as the source for copy data.
 type CopyFromSource interface {
 	// Next returns true if there is another row and makes the next row data
@@ -113,6 +250,7 @@ type copyFrom struct {
 	rowSrc        CopyFromSource
 	readerErrChan chan error
 	mode          QueryExecMode
+	encodeWorkers int
 }
 
 func (ct *copyFrom) run(ctx context.Context) (int64, error) {
@@ -164,12 +302,15 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 	go func() {
 		defer close(doneChan)
 
+		if ct.encodeWorkers > 1 {
+			ct.encodeParallel(w, sd)
+			return
+		}
+
 		// Purposely NOT using defer w.Close(). See https://github.com/golang/go/issues/24283.
 		buf := ct.conn.wbuf
 
-		buf = append(buf, "PGCOPY\n\377\r\n\000"...)
-		buf = pgio.AppendInt32(buf, 0)
-		buf = pgio.AppendInt32(buf, 0)
+		buf = pgcopy.AppendHeader(buf)
 
 		moreRows := true
 		for moreRows {
@@ -232,7 +373,7 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (b
 
 		buf = pgio.AppendInt16(buf, int16(len(ct.columnNames)))
 		for i, val := range values {
-			buf, err = encodeCopyValue(ct.conn.typeMap, buf, sd.Fields[i].DataTypeOID, val)
+			buf, err = pgcopy.AppendValue(ct.conn.typeMap, buf, sd.Fields[i].DataTypeOID, val)
 			if err != nil {
 				return false, nil, err
 			}
@@ -254,6 +395,149 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (b
 	return false, buf, nil
 }
 
+// copyFromChunkRows is the number of rows encoded together by a worker when CopyFrom encodes rows in parallel.
+const copyFromChunkRows = 256
+
+// copyFromChunk is a chunk of rows encoded by a worker when CopyFrom encodes rows in parallel.
+type copyFromChunk struct {
+	rows [][]any
+	buf  []byte
+	done chan struct{}
+}
+
+// encodeParallel reads rows from ct.rowSrc and encodes them in chunks on ct.encodeWorkers goroutines. The encoded
+// chunks are written to w in the order of the rows. At most 2 chunks per worker are buffered. The first error stops
+// reading rows and is passed to w.
+func (ct *copyFrom) encodeParallel(w *io.PipeWriter, sd *pgconn.StatementDescription) {
+	jobs := make(chan *copyFromChunk)
+	ordered := make(chan *copyFromChunk, ct.encodeWorkers*2)
+	quit := make(chan struct{})
+
+	var abortOnce sync.Once
+	var abortErr error
+	abort := func(err error) {
+		abortOnce.Do(func() {
+			abortErr = err
+			close(quit)
+		})
+	}
+
+	// Each worker uses its own copy of the type map as a pgtype.Map is not safe for concurrent use.
+	var wg sync.WaitGroup
+	for i := 0; i < ct.encodeWorkers; i++ {
+		wg.Add(1)
+		m := ct.conn.typeMap.Copy()
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				var err error
+				chunk.buf, err = ct.encodeRows(m, chunk.buf, sd, chunk.rows)
+				if err != nil {
+					abort(err)
+				}
+				close(chunk.done)
+			}
+		}()
+	}
+
+	// Rows are read on a single goroutine as a CopyFromSource is not safe for concurrent use.
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+
+		for {
+			chunk := &copyFromChunk{rows: make([][]any, 0, copyFromChunkRows), done: make(chan struct{})}
+			for len(chunk.rows) < copyFromChunkRows && ct.rowSrc.Next() {
+				values, err := ct.rowSrc.Values()
+				if err != nil {
+					abort(err)
+					return
+				}
+				// The source may reuse the values slice for the next row.
+				chunk.rows = append(chunk.rows, append([]any(nil), values...))
+			}
+			if err := ct.rowSrc.Err(); err != nil {
+				abort(err)
+				return
+			}
+			if len(chunk.rows) == 0 {
+				return
+			}
+
+			select {
+			case ordered <- chunk:
+			case <-quit:
+				return
+			}
+			select {
+			case jobs <- chunk:
+			case <-quit:
+				return
+			}
+
+			if len(chunk.rows) < copyFromChunkRows {
+				return
+			}
+		}
+	}()
+
+	_, err := w.Write(pgcopy.AppendHeader(ct.conn.wbuf))
+	if err != nil {
+		abort(err)
+	}
+
+	for chunk := range ordered {
+		select {
+		case <-chunk.done:
+		case <-quit:
+		}
+
+		select {
+		case <-quit:
+		default:
+			_, err := w.Write(chunk.buf)
+			if err != nil {
+				abort(err)
+			}
+			continue
+		}
+		break
+	}
+
+	select {
+	case <-quit:
+		// Wait for the reader and the workers to stop before returning as the row source must not be used after
+		// CopyFrom returns.
+		for range ordered {
+		}
+		wg.Wait()
+		w.CloseWithError(abortErr)
+	default:
+		wg.Wait()
+		w.Close()
+	}
+}
+
+// encodeRows appends the binary copy encoding of rows to buf.
+func (ct *copyFrom) encodeRows(m *pgtype.Map, buf []byte, sd *pgconn.StatementDescription, rows [][]any) ([]byte, error) {
+	for _, values := range rows {
+		if len(values) != len(ct.columnNames) {
+			return nil, fmt.Errorf("expected %d values, got %d values", len(ct.columnNames), len(values))
+		}
+
+		buf = pgio.AppendInt16(buf, int16(len(ct.columnNames)))
+		for i, val := range values {
+			var err error
+			buf, err = pgcopy.AppendValue(m, buf, sd.Fields[i].DataTypeOID, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buf, nil
+}
+
 // CopyFrom uses the PostgreSQL copy protocol to perform bulk data insertion. It returns the number of rows copied and
 // an error.
 //
@@ -263,13 +547,19 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (b
 // Even though enum types appear to be strings they still must be registered to use with CopyFrom. This can be done with
 // Conn.LoadType and pgtype.Map.RegisterType.
 func (c *Conn) CopyFrom(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource) (int64, error) {
+	return c.copyFrom(ctx, tableName, columnNames, rowSrc, c.config.DefaultQueryExecMode)
+}
+
+// copyFrom is CopyFrom with the query exec mode used to get the column types.
+func (c *Conn) copyFrom(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, mode QueryExecMode) (int64, error) {
 	ct := &copyFrom{
 		conn:          c,
 		tableName:     tableName,
 		columnNames:   columnNames,
 		rowSrc:        rowSrc,
 		readerErrChan: make(chan error),
-		mode:          c.config.DefaultQueryExecMode,
+		mode:          mode,
+		encodeWorkers: c.config.CopyFromEncodeWorkers,
 	}
 
 	return ct.run(ctx)
@@ -0,0 +1,248 @@
+package pgx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgcopy"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CopyFromCSVOptions configures CopyFromCSV.
+type CopyFromCSVOptions struct {
+	// Comma is the field delimiter. If zero, ',' is used.
+	Comma rune
+
+	// Header indicates that the first record is a header. It is not copied. If the column names passed to CopyFromCSV
+	// are nil they are taken from the header.
+	Header bool
+
+	// Null is the field value that is copied as NULL. The default of "" matches the PostgreSQL CSV format, except that
+	// a quoted empty string is also copied as NULL because quoting is not preserved by the CSV parser.
+	Null string
+
+	// MaxRejected is the number of rejected records after which the copy is aborted. If zero, any number of records
+	// may be rejected.
+	MaxRejected int
+
+	// OnReject is called for each rejected record. If it returns an error the copy is aborted with that error. If
+	// OnReject is nil rejected records are collected in CopyFromCSVResult.Rejected. OnReject is called from a separate
+	// goroutine while the copy is in progress.
+	OnReject func(rejected CSVRejectedRecord) error
+}
+
+// CSVRejectedRecord is a CSV record that was not copied.
+type CSVRejectedRecord struct {
+	// Line is the line number where the record starts. Line numbers start at 1.
+	Line int
+
+	// Record is the parsed fields of the record. It is nil if the record could not be parsed.
+	Record []string
+
+	// Err is the reason the record was rejected.
+	Err error
+}
+
+// CopyFromCSVResult is the result of CopyFromCSV.
+type CopyFromCSVResult struct {
+	// Copied is the number of copied rows.
+	Copied int64
+
+	// Rejected is the rejected records if CopyFromCSVOptions.OnReject is nil.
+	Rejected []CSVRejectedRecord
+}
+
+// ErrTooManyRejectedRecords is returned by CopyFromCSV when more than CopyFromCSVOptions.MaxRejected records are
+// rejected.
+var ErrTooManyRejectedRecords = errors.New("too many rejected records")
+
+// CopyFromCSV reads CSV records from r and copies them into the columns columnNames of tableName. Each field is parsed
+// client-side as the text format of the type of its column with the connection's type map. Records that cannot be
+// parsed or that have the wrong number of fields are rejected instead of failing the copy. The valid records are
+// streamed to the server with the binary COPY format.
+//
+// A type must be registered for the type of each column. Rows can still be rejected by the server (e.g. because of a
+// constraint violation). In that case the whole copy fails as with CopyFrom.
+func (c *Conn) CopyFromCSV(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromCSVOptions) (CopyFromCSVResult, error) {
+	csvReader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		csvReader.Comma = opts.Comma
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	if opts.Header {
+		header, err := csvReader.Read()
+		if err != nil {
+			return CopyFromCSVResult{}, fmt.Errorf("failed to read header: %w", err)
+		}
+		if columnNames == nil {
+			columnNames = append([]string(nil), header...)
+		}
+	}
+
+	if len(columnNames) == 0 {
+		return CopyFromCSVResult{}, errors.New("no columns to copy")
+	}
+
+	quotedColumnNames := quoteColumnNames(columnNames)
+	sd, err := c.Prepare(ctx, "", "select "+quotedColumnNames+" from "+tableName.Sanitize())
+	if err != nil {
+		return CopyFromCSVResult{}, fmt.Errorf("statement description failed: %w", err)
+	}
+
+	oids := make([]uint32, len(sd.Fields))
+	for i := range sd.Fields {
+		oids[i] = sd.Fields[i].DataTypeOID
+		if _, ok := c.typeMap.TypeForOID(oids[i]); !ok {
+			return CopyFromCSVResult{}, fmt.Errorf("type of column %s is not registered", columnNames[i])
+		}
+	}
+
+	cc := &csvCopy{
+		typeMap:   c.typeMap,
+		csvReader: csvReader,
+		oids:      oids,
+		opts:      opts,
+	}
+
+	pr, pw := io.Pipe()
+	doneChan := make(chan struct{})
+
+	var runErr error
+	go func() {
+		defer close(doneChan)
+		runErr = cc.run(pw)
+		pw.CloseWithError(runErr)
+	}()
+
+	commandTag, err := c.pgConn.CopyFrom(ctx, pr, "copy "+tableName.Sanitize()+" ( "+quotedColumnNames+" ) from stdin binary;")
+
+	pr.Close()
+	<-doneChan
+
+	result := CopyFromCSVResult{Copied: commandTag.RowsAffected(), Rejected: cc.rejected}
+	if err != nil {
+		result.Copied = 0
+		// Prefer the error that caused the copy to be aborted over the resulting server error. A failed write is the result
+		// of the copy being aborted, e.g. by the server, not its cause.
+		if runErr != nil && !cc.writeFailed {
+			err = runErr
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// csvCopy converts CSV records to the binary COPY format.
+type csvCopy struct {
+	typeMap   *pgtype.Map
+	csvReader *csv.Reader
+	oids      []uint32
+	opts      CopyFromCSVOptions
+
+	rejected      []CSVRejectedRecord
+	rejectedCount int
+	writeFailed   bool
+}
+
+func (cc *csvCopy) run(w io.Writer) error {
+	const sendBufSize = 65536 - 5 // The packet has a 5-byte header
+
+	buf := pgcopy.AppendHeader(nil)
+
+	for {
+		record, err := cc.csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			err = cc.reject(CSVRejectedRecord{Line: parseErr.StartLine, Err: err})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, _ := cc.csvReader.FieldPos(0)
+		rowBuf, err := cc.appendRecord(buf, record)
+		if err != nil {
+			err = cc.reject(CSVRejectedRecord{Line: line, Record: append([]string(nil), record...), Err: err})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		buf = rowBuf
+
+		if len(buf) > sendBufSize {
+			err = cc.write(w, buf)
+			if err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+
+	buf = pgcopy.AppendTrailer(buf)
+	return cc.write(w, buf)
+}
+
+func (cc *csvCopy) write(w io.Writer, buf []byte) error {
+	_, err := w.Write(buf)
+	if err != nil {
+		cc.writeFailed = true
+	}
+	return err
+}
+
+func (cc *csvCopy) appendRecord(buf []byte, record []string) ([]byte, error) {
+	if len(record) != len(cc.oids) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cc.oids), len(record))
+	}
+
+	values := make([]any, len(record))
+	for i, field := range record {
+		if field == cc.opts.Null {
+			continue
+		}
+
+		err := cc.typeMap.Scan(cc.oids[i], TextFormatCode, []byte(field), &values[i])
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", i+1, err)
+		}
+	}
+
+	buf, err := pgcopy.AppendRow(cc.typeMap, buf, cc.oids, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func (cc *csvCopy) reject(rejected CSVRejectedRecord) error {
+	cc.rejectedCount++
+	if cc.opts.OnReject != nil {
+		err := cc.opts.OnReject(rejected)
+		if err != nil {
+			return err
+		}
+	} else {
+		cc.rejected = append(cc.rejected, rejected)
+	}
+
+	if cc.opts.MaxRejected > 0 && cc.rejectedCount > cc.opts.MaxRejected {
+		return fmt.Errorf("%w: line %d: %v", ErrTooManyRejectedRecords, rejected.Line, rejected.Err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCopyFromCSV(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_from_csv(id int4, name text, born date)`)
+
+		input := `id,name,born
+1,Alice,1990-01-02
+two,Bob,1991-02-03
+3,,1992-03-04
+4,Dan,1993-13-01
+5,"Eve
+Smith",1994-05-06
+6,Frank
+`
+
+		result, err := conn.CopyFromCSV(ctx, pgx.Identifier{"copy_from_csv"}, nil, strings.NewReader(input), pgx.CopyFromCSVOptions{Header: true})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, result.Copied)
+
+		require.Len(t, result.Rejected, 3)
+		assert.Equal(t, 3, result.Rejected[0].Line)
+		assert.Equal(t, []string{"two", "Bob", "1991-02-03"}, result.Rejected[0].Record)
+		assert.Error(t, result.Rejected[0].Err)
+		assert.Equal(t, 5, result.Rejected[1].Line)
+		assert.Equal(t, 8, result.Rejected[2].Line)
+
+		type row struct {
+			ID   int32
+			Name *string
+		}
+		rows, err := conn.Query(ctx, "select id, name from copy_from_csv order by id")
+		require.NoError(t, err)
+		actual, err := pgx.CollectRows(rows, pgx.RowToStructByName[row])
+		require.NoError(t, err)
+		require.Len(t, actual, 3)
+		assert.EqualValues(t, 1, actual[0].ID)
+		assert.Nil(t, actual[1].Name)
+		assert.Equal(t, "Eve\nSmith", *actual[2].Name)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyFromCSVMaxRejected(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_from_csv(a int4, b int4)`)
+
+		input := "1;2\nx;2\n3;y\n4;4\n"
+
+		_, err := conn.CopyFromCSV(ctx, pgx.Identifier{"copy_from_csv"}, []string{"a", "b"}, strings.NewReader(input), pgx.CopyFromCSVOptions{Comma: ';', MaxRejected: 1})
+		require.ErrorIs(t, err, pgx.ErrTooManyRejectedRecords)
+
+		var rejectedLines []int
+		errStop := errors.New("stop")
+		_, err = conn.CopyFromCSV(ctx, pgx.Identifier{"copy_from_csv"}, []string{"a", "b"}, strings.NewReader(input), pgx.CopyFromCSVOptions{
+			Comma: ';',
+			OnReject: func(rejected pgx.CSVRejectedRecord) error {
+				rejectedLines = append(rejectedLines, rejected.Line)
+				if len(rejectedLines) == 2 {
+					return errStop
+				}
+				return nil
+			},
+		})
+		require.ErrorIs(t, err, errStop)
+		assert.Equal(t, []int{2, 3}, rejectedLines)
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from copy_from_csv").Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, n)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyFromCSVServerErrorOnLargeInput(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_from_csv(a int4 primary key, b text)`)
+
+		// The duplicate key aborts the copy on the server while most of the input has not been sent yet.
+		var sb strings.Builder
+		sb.WriteString("1,a\n1,a\n")
+		for i := 2; i < 500000; i++ {
+			sb.WriteString(strconv.Itoa(i))
+			sb.WriteString(",abcdefghijklmnopqrstuvwxyz\n")
+		}
+
+		_, err := conn.CopyFromCSV(ctx, pgx.Identifier{"copy_from_csv"}, []string{"a", "b"}, strings.NewReader(sb.String()), pgx.CopyFromCSVOptions{})
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "23505", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
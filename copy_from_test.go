@@ -858,3 +858,203 @@ func TestCopyFromFunc(t *testing.T) {
 
 	ensureConnValid(t, conn)
 }
+
+func TestCopyFromStructsColumnNames(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		ID        int64
+		CreatedAt time.Time
+	}
+
+	type person struct {
+		Base
+		FirstName  string
+		LastName   string `db:"surname"`
+		HTTPStatus int16
+		Ignored    string `db:"-"`
+		Age        *int32 `db:"age,omitempty"`
+		private    string
+	}
+
+	src := pgx.CopyFromStructs([]person{{}})
+	require.NoError(t, src.Err())
+	require.Equal(t, []string{"id", "createdat", "firstname", "surname", "httpstatus", "age"}, src.ColumnNames())
+
+	ptrSrc := pgx.CopyFromStructs([]*person{{private: "x"}})
+	require.Equal(t, src.ColumnNames(), ptrSrc.ColumnNames())
+
+	invalidSrc := pgx.CopyFromStructs([]int{1})
+	require.False(t, invalidSrc.Next())
+	require.Error(t, invalidSrc.Err())
+}
+
+func TestCopyFromStructsValues(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID   int32
+		Name string
+	}
+
+	src := pgx.CopyFromStructs([]item{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+
+	require.True(t, src.Next())
+	values, err := src.Values()
+	require.NoError(t, err)
+	require.Equal(t, []any{int32(1), "a"}, values)
+
+	require.True(t, src.Next())
+	values2, err := src.Values()
+	require.NoError(t, err)
+	require.Equal(t, []any{int32(2), "b"}, values2)
+
+	// The values slice is reused for each row.
+	require.Same(t, &values[0], &values2[0])
+
+	require.False(t, src.Next())
+	require.NoError(t, src.Err())
+}
+
+func TestConnCopyFromStructs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_from_structs(
+			id int8,
+			firstname text,
+			surname text,
+			httpstatus int2,
+			age int4
+		)`)
+
+		type Base struct {
+			ID int64
+		}
+
+		type person struct {
+			Base
+			FirstName  string
+			LastName   string `db:"surname"`
+			HTTPStatus int16
+			Ignored    string `db:"-"`
+			Age        *int32
+		}
+
+		age := int32(42)
+		input := []*person{
+			{Base: Base{ID: 1}, FirstName: "John", LastName: "Smith", HTTPStatus: 200, Ignored: "x", Age: &age},
+			{Base: Base{ID: 2}, FirstName: "Jane", LastName: "Doe", HTTPStatus: 404},
+		}
+
+		src := pgx.CopyFromStructs(input)
+		copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"copy_from_structs"}, src.ColumnNames(), src)
+		require.NoError(t, err)
+		require.EqualValues(t, len(input), copyCount)
+
+		// The untagged multi-word fields are read back by RowToStructByName from the columns they were copied to.
+		rows, err := conn.Query(ctx, "select * from copy_from_structs order by id")
+		require.NoError(t, err)
+		output, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[person])
+		require.NoError(t, err)
+
+		for _, p := range input {
+			p.Ignored = ""
+		}
+		require.Equal(t, input, output)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyFromEncodeWorkers(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.CopyFromEncodeWorkers = 4
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(
+		a int8,
+		b numeric,
+		c text[]
+	)`)
+
+	var inputRows [][]any
+	for i := 0; i < 10000; i++ {
+		inputRows = append(inputRows, []any{int64(i), fmt.Sprint(i), []string{"a", fmt.Sprint(i)}})
+	}
+
+	copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b", "c"}, pgx.CopyFromRows(inputRows))
+	require.NoError(t, err)
+	require.EqualValues(t, len(inputRows), copyCount)
+
+	// Without an ORDER BY the rows of a freshly filled table are returned in insertion order.
+	rows, err := conn.Query(ctx, "select a, b::text, c from foo")
+	require.NoError(t, err)
+	i := 0
+	for rows.Next() {
+		var a int64
+		var b string
+		var c []string
+		require.NoError(t, rows.Scan(&a, &b, &c))
+		require.EqualValues(t, i, a)
+		require.Equal(t, fmt.Sprint(i), b)
+		require.Equal(t, []string{"a", fmt.Sprint(i)}, c)
+		i++
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, len(inputRows), i)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromEncodeWorkersError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.CopyFromEncodeWorkers = 4
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(a int8)`)
+
+	var inputRows [][]any
+	for i := 0; i < 10000; i++ {
+		inputRows = append(inputRows, []any{int64(i)})
+	}
+	inputRows[5000] = []any{"not a number"}
+
+	copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a"}, pgx.CopyFromRows(inputRows))
+	require.Error(t, err)
+	require.EqualValues(t, 0, copyCount)
+
+	i := 0
+	srcErr := fmt.Errorf("source error")
+	copyCount, err = conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a"}, pgx.CopyFromFunc(func() ([]any, error) {
+		i++
+		if i == 5000 {
+			return nil, srcErr
+		}
+		return []any{int64(i)}, nil
+	}))
+	require.ErrorIs(t, err, srcErr)
+	require.EqualValues(t, 0, copyCount)
+
+	var n int64
+	err = conn.QueryRow(ctx, "select count(*) from foo").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, n)
+
+	ensureConnValid(t, conn)
+}
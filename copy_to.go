@@ -0,0 +1,230 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgcopy"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CopyToRows runs sql with COPY (sql) TO STDOUT (FORMAT binary) and returns the copied rows as Rows. sql must be a
+// query that can be used with COPY, such as a SELECT without parameters. The field descriptions are determined by
+// describing sql before the copy is started. This combines the throughput of COPY with Scan and CollectRows.
+//
+// As with Query, the returned Rows must be closed before the connection can be used again. Closing the Rows early
+// reads and discards the remaining copy data.
+func (c *Conn) CopyToRows(ctx context.Context, sql string) (Rows, error) {
+	sd, err := c.pgConn.Prepare(ctx, "", sql, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldDescriptions := make([]pgconn.FieldDescription, len(sd.Fields))
+	copy(fieldDescriptions, sd.Fields)
+	for i := range fieldDescriptions {
+		fieldDescriptions[i].Format = BinaryFormatCode
+	}
+
+	oids := make([]uint32, len(fieldDescriptions))
+	for i := range fieldDescriptions {
+		oids[i] = fieldDescriptions[i].DataTypeOID
+	}
+
+	pr, pw := io.Pipe()
+	rows := &copyToRows{
+		typeMap:           c.typeMap,
+		conn:              c,
+		fieldDescriptions: fieldDescriptions,
+		pr:                pr,
+		copyReader:        pgcopy.NewReader(pr, c.typeMap, oids),
+		doneChan:          make(chan struct{}),
+	}
+
+	go func() {
+		defer close(rows.doneChan)
+		rows.commandTag, rows.copyErr = c.pgConn.CopyTo(ctx, pw, "copy ("+sql+") to stdout (format binary)")
+		pw.CloseWithError(rows.copyErr)
+	}()
+
+	return rows, nil
+}
+
+// copyToRows implements the Rows interface for Conn.CopyToRows.
+type copyToRows struct {
+	typeMap           *pgtype.Map
+	conn              *Conn
+	fieldDescriptions []pgconn.FieldDescription
+
+	pr         *io.PipeReader
+	copyReader *pgcopy.Reader
+	doneChan   chan struct{}
+
+	values [][]byte
+
+	scanPlans []pgtype.ScanPlan
+	scanTypes []reflect.Type
+
+	commandTag pgconn.CommandTag
+	copyErr    error
+	err        error
+	closed     bool
+}
+
+func (rows *copyToRows) Close() {
+	if rows.closed {
+		return
+	}
+	rows.closed = true
+
+	// Discard the remaining copy data so the copy can complete without breaking the connection.
+	io.Copy(io.Discard, rows.pr)
+	<-rows.doneChan
+
+	if rows.err == nil {
+		rows.err = rows.copyErr
+	}
+}
+
+func (rows *copyToRows) Err() error {
+	return rows.err
+}
+
+func (rows *copyToRows) CommandTag() pgconn.CommandTag {
+	return rows.commandTag
+}
+
+func (rows *copyToRows) FieldDescriptions() []pgconn.FieldDescription {
+	return rows.fieldDescriptions
+}
+
+func (rows *copyToRows) fatal(err error) {
+	if rows.err != nil {
+		return
+	}
+
+	rows.err = err
+	rows.Close()
+}
+
+func (rows *copyToRows) Next() bool {
+	if rows.closed {
+		return false
+	}
+
+	if !rows.copyReader.Next() {
+		err := rows.copyReader.Err()
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			// The copy data ended early because the copy failed.
+			<-rows.doneChan
+			if rows.copyErr != nil {
+				err = rows.copyErr
+			}
+		}
+		if err != nil {
+			rows.fatal(err)
+		} else {
+			rows.Close()
+		}
+		return false
+	}
+
+	rows.values = rows.copyReader.RawValues()
+	return true
+}
+
+func (rows *copyToRows) Scan(dest ...any) error {
+	m := rows.typeMap
+	fieldDescriptions := rows.fieldDescriptions
+	values := rows.values
+
+	if len(dest) == 1 {
+		if rc, ok := dest[0].(RowScanner); ok {
+			err := rc.ScanRow(rows)
+			if err != nil {
+				rows.fatal(err)
+			}
+			return err
+		}
+	}
+
+	if len(fieldDescriptions) != len(dest) {
+		err := fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(fieldDescriptions), len(dest))
+		rows.fatal(err)
+		return err
+	}
+
+	if rows.scanPlans == nil {
+		rows.scanPlans = make([]pgtype.ScanPlan, len(values))
+		rows.scanTypes = make([]reflect.Type, len(values))
+		for i := range dest {
+			rows.scanPlans[i] = m.PlanScan(fieldDescriptions[i].DataTypeOID, fieldDescriptions[i].Format, dest[i])
+			rows.scanTypes[i] = reflect.TypeOf(dest[i])
+		}
+	}
+
+	for i, dst := range dest {
+		if dst == nil {
+			continue
+		}
+
+		if rows.scanTypes[i] != reflect.TypeOf(dst) {
+			rows.scanPlans[i] = m.PlanScan(fieldDescriptions[i].DataTypeOID, fieldDescriptions[i].Format, dest[i])
+			rows.scanTypes[i] = reflect.TypeOf(dest[i])
+		}
+
+		err := rows.scanPlans[i].Scan(values[i], dst)
+		if err != nil {
+			err = ScanArgError{ColumnIndex: i, Err: err}
+			rows.fatal(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rows *copyToRows) Values() ([]any, error) {
+	if rows.closed {
+		return nil, errors.New("rows is closed")
+	}
+
+	values := make([]any, 0, len(rows.fieldDescriptions))
+
+	for i := range rows.fieldDescriptions {
+		buf := rows.values[i]
+		fd := &rows.fieldDescriptions[i]
+
+		if buf == nil {
+			values = append(values, nil)
+			continue
+		}
+
+		if dt, ok := rows.typeMap.TypeForOID(fd.DataTypeOID); ok {
+			value, err := dt.Codec.DecodeValue(rows.typeMap, fd.DataTypeOID, fd.Format, buf)
+			if err != nil {
+				rows.fatal(err)
+				return nil, err
+			}
+			values = append(values, value)
+		} else {
+			newBuf := make([]byte, len(buf))
+			copy(newBuf, buf)
+			values = append(values, newBuf)
+		}
+	}
+
+	return values, nil
+}
+
+func (rows *copyToRows) RawValues() [][]byte {
+	return rows.values
+}
+
+func (rows *copyToRows) Conn() *Conn {
+	return rows.conn
+}
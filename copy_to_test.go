@@ -0,0 +1,129 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCopyToRows(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type row struct {
+			N    int32
+			Name *string
+			Ts   time.Time
+		}
+
+		rows, err := conn.CopyToRows(ctx, `select n, case when n % 2 = 0 then 'name ' || n end as name, '2020-01-01 00:00:00Z'::timestamptz + n * interval '1 day' as ts
+from generate_series(1, 1000) n`)
+		require.NoError(t, err)
+
+		require.Len(t, rows.FieldDescriptions(), 3)
+		assert.Equal(t, "name", rows.FieldDescriptions()[1].Name)
+
+		actual, err := pgx.CollectRows(rows, pgx.RowToStructByName[row])
+		require.NoError(t, err)
+		require.Len(t, actual, 1000)
+		assert.Equal(t, "COPY 1000", rows.CommandTag().String())
+
+		assert.EqualValues(t, 1, actual[0].N)
+		assert.Nil(t, actual[0].Name)
+		require.NotNil(t, actual[1].Name)
+		assert.Equal(t, "name 2", *actual[1].Name)
+		assert.True(t, actual[999].Ts.Equal(time.Date(2022, 9, 27, 0, 0, 0, 0, time.UTC)))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyToRowsEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.CopyToRows(ctx, `select '' as s, ''::bytea as b, null::text as n`)
+		require.NoError(t, err)
+
+		require.True(t, rows.Next())
+		assert.Equal(t, [][]byte{{}, {}, nil}, rows.RawValues())
+		require.NotNil(t, rows.RawValues()[0])
+		require.NotNil(t, rows.RawValues()[1])
+
+		values, err := rows.Values()
+		require.NoError(t, err)
+		assert.Equal(t, []any{"", []byte{}, nil}, values)
+
+		var s *string
+		var b []byte
+		var n *string
+		require.NoError(t, rows.Scan(&s, &b, &n))
+		require.NotNil(t, s)
+		assert.Equal(t, "", *s)
+		assert.NotNil(t, b)
+		assert.Empty(t, b)
+		assert.Nil(t, n)
+
+		require.False(t, rows.Next())
+		require.NoError(t, rows.Err())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyToRowsCloseEarly(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.CopyToRows(ctx, "select n, n::text from generate_series(1, 100000) n")
+		require.NoError(t, err)
+
+		require.True(t, rows.Next())
+		values, err := rows.Values()
+		require.NoError(t, err)
+		assert.Equal(t, []any{int32(1), "1"}, values)
+
+		rows.Close()
+		require.NoError(t, rows.Err())
+		assert.Equal(t, "COPY 100000", rows.CommandTag().String())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyToRowsError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.CopyToRows(ctx, "select * from missing_table")
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "42P01", pgErr.Code)
+
+		rows, err := conn.CopyToRows(ctx, "select 1/(n - 5) from generate_series(1, 10) n")
+		require.NoError(t, err)
+		_, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
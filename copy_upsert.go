@@ -0,0 +1,170 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// CopyUpsertOptions configures how CopyUpsert resolves rows that conflict with existing rows.
+type CopyUpsertOptions struct {
+	// ConflictColumns is the conflict target. It must match a unique index or constraint of the table. If empty,
+	// conflicting rows are skipped with ON CONFLICT DO NOTHING.
+	ConflictColumns []string
+
+	// UpdateColumns are the columns updated with the copied values when a row conflicts. If nil, all copied columns that
+	// are not in ConflictColumns are updated.
+	UpdateColumns []string
+
+	// DoNothing skips conflicting rows instead of updating them.
+	DoNothing bool
+
+	// StagingTableName is the name of the temporary staging table. It must not be the name of an existing temporary
+	// table. If empty a unique name is generated.
+	StagingTableName string
+}
+
+var copyUpsertCount atomic.Int64
+
+// CopyUpsertResult is the number of rows inserted and updated by CopyUpsert.
+type CopyUpsertResult struct {
+	Inserted int64
+	Updated  int64
+}
+
+// CopyUpsert inserts the rows of rowSrc into tableName and resolves rows that conflict with existing rows according to
+// opts. The rows are first copied with CopyFrom into a temporary staging table with the types of columnNames in
+// tableName. They are then moved into tableName with INSERT ... SELECT ... ON CONFLICT. All of this is done in a single
+// transaction. If the connection is not already in a transaction one is started and committed by CopyUpsert. The copy
+// into the staging table is reported to the CopyFromTracer like CopyFrom.
+//
+// As with any INSERT ... ON CONFLICT DO UPDATE, rowSrc must not contain more than one row with the same conflict target
+// values.
+func (c *Conn) CopyUpsert(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyUpsertOptions) (CopyUpsertResult, error) {
+	if len(columnNames) == 0 {
+		return CopyUpsertResult{}, errors.New("no columns to copy")
+	}
+
+	if c.pgConn.TxStatus() == 'I' {
+		tx, err := c.Begin(ctx)
+		if err != nil {
+			return CopyUpsertResult{}, err
+		}
+		defer tx.Rollback(ctx)
+
+		result, err := c.copyUpsert(ctx, tableName, columnNames, rowSrc, opts)
+		if err != nil {
+			return CopyUpsertResult{}, err
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			return CopyUpsertResult{}, err
+		}
+
+		return result, nil
+	}
+
+	return c.copyUpsert(ctx, tableName, columnNames, rowSrc, opts)
+}
+
+func (c *Conn) copyUpsert(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyUpsertOptions) (CopyUpsertResult, error) {
+	// The staging table is dropped at the end so CopyUpsert can be called multiple times in a transaction. Its column
+	// types differ between calls so the statements that use it are not cached.
+	stagingTableName := Identifier{opts.StagingTableName}
+	if opts.StagingTableName == "" {
+		stagingTableName = Identifier{"pgx_copy_upsert_" + strconv.FormatInt(copyUpsertCount.Add(1), 10)}
+	}
+	quotedColumnNames := quoteColumnNames(columnNames)
+
+	_, err := c.Exec(ctx, "create temporary table "+stagingTableName.Sanitize()+" on commit drop as select "+quotedColumnNames+
+		" from "+tableName.Sanitize()+" with no data", QueryExecModeExec)
+	if err != nil {
+		return CopyUpsertResult{}, err
+	}
+
+	_, err = c.copyFrom(ctx, stagingTableName, columnNames, rowSrc, QueryExecModeDescribeExec)
+	if err != nil {
+		return CopyUpsertResult{}, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("with upserted as (insert into ")
+	sb.WriteString(tableName.Sanitize())
+	sb.WriteString(" (")
+	sb.WriteString(quotedColumnNames)
+	sb.WriteString(") select ")
+	sb.WriteString(quotedColumnNames)
+	sb.WriteString(" from ")
+	sb.WriteString(stagingTableName.Sanitize())
+	sb.WriteString(" on conflict")
+
+	updateColumns := opts.UpdateColumns
+	if updateColumns == nil {
+		for _, name := range columnNames {
+			if !containsString(opts.ConflictColumns, name) {
+				updateColumns = append(updateColumns, name)
+			}
+		}
+	}
+
+	if len(opts.ConflictColumns) == 0 || opts.DoNothing || len(updateColumns) == 0 {
+		if len(opts.ConflictColumns) > 0 {
+			sb.WriteString(" (")
+			sb.WriteString(quoteColumnNames(opts.ConflictColumns))
+			sb.WriteString(")")
+		}
+		sb.WriteString(" do nothing")
+	} else {
+		sb.WriteString(" (")
+		sb.WriteString(quoteColumnNames(opts.ConflictColumns))
+		sb.WriteString(") do update set ")
+		for i, name := range updateColumns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			quotedName := quoteIdentifier(name)
+			sb.WriteString(quotedName)
+			sb.WriteString(" = excluded.")
+			sb.WriteString(quotedName)
+		}
+	}
+
+	// xmax is 0 for a newly inserted row and set for an updated row.
+	sb.WriteString(" returning xmax = 0 as inserted) select count(*) filter (where inserted), count(*) filter (where not inserted) from upserted")
+
+	var result CopyUpsertResult
+	err = c.QueryRow(ctx, sb.String(), QueryExecModeExec).Scan(&result.Inserted, &result.Updated)
+	if err != nil {
+		return CopyUpsertResult{}, err
+	}
+
+	_, err = c.Exec(ctx, "drop table "+stagingTableName.Sanitize(), QueryExecModeExec)
+	if err != nil {
+		return CopyUpsertResult{}, err
+	}
+
+	return result, nil
+}
+
+func quoteColumnNames(columnNames []string) string {
+	var sb strings.Builder
+	for i, name := range columnNames {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quoteIdentifier(name))
+	}
+	return sb.String()
+}
+
+func containsString(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCopyUpsert(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_upsert(id int4 primary key, name text not null, n int4 not null default 0)`)
+		mustExec(t, conn, `insert into copy_upsert(id, name, n) values (1, 'a', 10), (2, 'b', 20)`)
+
+		result, err := conn.CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{
+			{int32(2), "B"},
+			{int32(3), "c"},
+			{int32(4), "d"},
+		}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}})
+		require.NoError(t, err)
+		assert.Equal(t, pgx.CopyUpsertResult{Inserted: 2, Updated: 1}, result)
+		assert.EqualValues(t, 'I', conn.PgConn().TxStatus())
+
+		result, err = conn.CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{
+			{int32(1), "A"},
+			{int32(5), "e"},
+		}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}, DoNothing: true})
+		require.NoError(t, err)
+		assert.Equal(t, pgx.CopyUpsertResult{Inserted: 1, Updated: 0}, result)
+
+		type row struct {
+			ID   int32
+			Name string
+			N    int32
+		}
+		rows, err := conn.Query(ctx, "select id, name, n from copy_upsert order by id")
+		require.NoError(t, err)
+		actual, err := pgx.CollectRows(rows, pgx.RowToStructByName[row])
+		require.NoError(t, err)
+		assert.Equal(t, []row{{1, "a", 10}, {2, "B", 20}, {3, "c", 0}, {4, "d", 0}, {5, "e", 0}}, actual)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyUpsertInTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_upsert(id int4 primary key, a text, b text)`)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		for i := 0; i < 2; i++ {
+			result, err := tx.Conn().CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "a", "b"}, pgx.CopyFromRows([][]any{
+				{int32(1), "a", "b"},
+			}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}, UpdateColumns: []string{"b"}})
+			require.NoError(t, err)
+			assert.Equal(t, pgx.CopyUpsertResult{Inserted: int64(1 - i), Updated: int64(i)}, result)
+		}
+
+		// Duplicate conflict targets in the source fail the whole upsert.
+		_, err = tx.Conn().CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "a", "b"}, pgx.CopyFromRows([][]any{
+			{int32(2), "a", "b"},
+			{int32(2), "a", "b"},
+		}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}})
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "21000", pgErr.Code)
+
+		require.NoError(t, tx.Rollback(ctx))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyUpsertStagingTable(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table copy_upsert(id int4 primary key, name text not null)`)
+		// A caller table with the name of a staging table must not collide.
+		mustExec(t, conn, `create temporary table pgx_copy_upsert(id int4)`)
+
+		var tracedTableName pgx.Identifier
+		tracer.traceCopyFromStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+			tracedTableName = data.TableName
+			return ctx
+		}
+		traceCopyFromEndCalled := false
+		tracer.traceCopyFromEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+			traceCopyFromEndCalled = true
+			require.NoError(t, data.Err)
+			require.EqualValues(t, 1, data.CommandTag.RowsAffected())
+		}
+		defer func() {
+			tracer.traceCopyFromStart = nil
+			tracer.traceCopyFromEnd = nil
+		}()
+
+		result, err := conn.CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{
+			{int32(1), "a"},
+		}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}})
+		require.NoError(t, err)
+		assert.Equal(t, pgx.CopyUpsertResult{Inserted: 1}, result)
+		require.True(t, traceCopyFromEndCalled)
+		require.Len(t, tracedTableName, 1)
+		assert.NotEqual(t, "pgx_copy_upsert", tracedTableName[0])
+
+		result, err = conn.CopyUpsert(ctx, pgx.Identifier{"copy_upsert"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{
+			{int32(1), "b"},
+		}), pgx.CopyUpsertOptions{ConflictColumns: []string{"id"}, StagingTableName: "my_staging"})
+		require.NoError(t, err)
+		assert.Equal(t, pgx.CopyUpsertResult{Updated: 1}, result)
+		assert.Equal(t, pgx.Identifier{"my_staging"}, tracedTableName)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,201 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrCursorClosed is returned when a closed Cursor is used. A Cursor is closed by Close and when the transaction it was
+// declared in ends, unless it was declared WITH HOLD and the transaction was committed.
+var ErrCursorClosed = errors.New("cursor closed")
+
+// CursorQuerier is the interface used by a Cursor to execute its statements. It is implemented by *Conn and Tx.
+type CursorQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error)
+	Query(ctx context.Context, sql string, args ...any) (Rows, error)
+}
+
+// CursorOptions are the options used to declare a Cursor.
+type CursorOptions struct {
+	// Name is the name of the cursor. If empty a unique name is generated.
+	Name string
+
+	// Hold declares the cursor WITH HOLD. Such a cursor can be used after the transaction that declared it is committed,
+	// and can be declared outside of a transaction. It must be closed explicitly.
+	Hold bool
+
+	// Scroll declares the cursor SCROLL. This is required to fetch or move backward.
+	Scroll bool
+}
+
+var cursorCount atomic.Int64
+
+// Cursor is a server-side cursor. It allows reading the results of a query in batches without holding them in memory.
+//
+// A Cursor that is not declared WITH HOLD must be declared in a transaction. A Cursor declared with a Tx that
+// implements CursorRegistry, such as the Tx of this package and pgxpool.Tx, is closed when the transaction ends. A
+// Cursor declared WITH HOLD remains open when the transaction is committed and then uses the connection of the
+// transaction.
+//
+// For more details see: https://www.postgresql.org/docs/current/sql-declare.html
+type Cursor struct {
+	db     CursorQuerier
+	name   string
+	hold   bool
+	closed bool
+}
+
+// CursorRegistry is implemented by transactions that close the cursors declared in them when they end. A type that
+// wraps a Tx should implement it by forwarding to the wrapped Tx.
+type CursorRegistry interface {
+	// RegisterCursor is called by DeclareCursor after cursor has been declared.
+	RegisterCursor(cursor *Cursor)
+}
+
+// DeclareCursor declares a cursor for sql with args on db. db is typically a Tx or a *Conn. The cursor is declared with
+// QueryExecModeExec and a QueryExecMode may be given as the first argument to override it.
+func DeclareCursor(ctx context.Context, db CursorQuerier, opts CursorOptions, sql string, args ...any) (*Cursor, error) {
+	name := opts.Name
+	if name == "" {
+		name = "pgx_cursor_" + strconv.FormatInt(cursorCount.Add(1), 10)
+	}
+
+	cursor := &Cursor{
+		db:   db,
+		name: quoteIdentifier(name),
+		hold: opts.Hold,
+	}
+
+	var sb strings.Builder
+	sb.WriteString("declare ")
+	sb.WriteString(cursor.name)
+	if opts.Scroll {
+		sb.WriteString(" scroll")
+	} else {
+		sb.WriteString(" no scroll")
+	}
+	sb.WriteString(" cursor")
+	if opts.Hold {
+		sb.WriteString(" with hold")
+	}
+	sb.WriteString(" for ")
+	sb.WriteString(sql)
+
+	_, err := db.Exec(ctx, sb.String(), append([]any{QueryExecModeExec}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := db.(CursorRegistry); ok {
+		r.RegisterCursor(cursor)
+	}
+
+	return cursor, nil
+}
+
+// Name returns the quoted name of the cursor as used in SQL.
+func (c *Cursor) Name() string {
+	return c.name
+}
+
+// Fetch fetches the next n rows. If n is negative the previous -n rows are fetched in reverse order, which requires a
+// scroll cursor. The returned Rows work with CollectRows and the other row helpers. Fewer than n rows are returned when
+// the end of the result is reached.
+func (c *Cursor) Fetch(ctx context.Context, n int64) (Rows, error) {
+	if n < 0 {
+		return c.fetch(ctx, "backward "+strconv.FormatInt(-n, 10))
+	}
+	return c.fetch(ctx, "forward "+strconv.FormatInt(n, 10))
+}
+
+// FetchAll fetches all remaining rows.
+func (c *Cursor) FetchAll(ctx context.Context) (Rows, error) {
+	return c.fetch(ctx, "forward all")
+}
+
+// FetchAbsolute positions the cursor on row pos and fetches it. Negative positions count from the end of the result.
+// Positioning before the current row requires a scroll cursor.
+func (c *Cursor) FetchAbsolute(ctx context.Context, pos int64) (Rows, error) {
+	return c.fetch(ctx, "absolute "+strconv.FormatInt(pos, 10))
+}
+
+// FetchRelative moves the cursor offset rows relative to the current row and fetches that row. Moving backward
+// requires a scroll cursor.
+func (c *Cursor) FetchRelative(ctx context.Context, offset int64) (Rows, error) {
+	return c.fetch(ctx, "relative "+strconv.FormatInt(offset, 10))
+}
+
+func (c *Cursor) fetch(ctx context.Context, direction string) (Rows, error) {
+	if c.closed {
+		err := ErrCursorClosed
+		return &baseRows{closed: true, err: err}, err
+	}
+
+	return c.db.Query(ctx, "fetch "+direction+" from "+c.name, QueryExecModeExec)
+}
+
+// Move moves the cursor n rows without fetching them. If n is negative the cursor moves backward, which requires a
+// scroll cursor. It returns the number of rows moved over.
+func (c *Cursor) Move(ctx context.Context, n int64) (int64, error) {
+	if n < 0 {
+		return c.move(ctx, "backward "+strconv.FormatInt(-n, 10))
+	}
+	return c.move(ctx, "forward "+strconv.FormatInt(n, 10))
+}
+
+// MoveAbsolute positions the cursor on row pos without fetching it. Negative positions count from the end of the
+// result. Position 0 is before the first row.
+func (c *Cursor) MoveAbsolute(ctx context.Context, pos int64) (int64, error) {
+	return c.move(ctx, "absolute "+strconv.FormatInt(pos, 10))
+}
+
+// MoveRelative moves the cursor offset rows relative to the current row without fetching it.
+func (c *Cursor) MoveRelative(ctx context.Context, offset int64) (int64, error) {
+	return c.move(ctx, "relative "+strconv.FormatInt(offset, 10))
+}
+
+func (c *Cursor) move(ctx context.Context, direction string) (int64, error) {
+	if c.closed {
+		return 0, ErrCursorClosed
+	}
+
+	commandTag, err := c.db.Exec(ctx, "move "+direction+" in "+c.name, QueryExecModeExec)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// Close closes the cursor. It is safe to call Close multiple times.
+func (c *Cursor) Close(ctx context.Context) error {
+	if c.closed {
+		return nil
+	}
+
+	_, err := c.db.Exec(ctx, "close "+c.name, QueryExecModeExec)
+	c.closed = true
+	return err
+}
+
+// IsClosed returns true if the cursor is closed.
+func (c *Cursor) IsClosed() bool {
+	return c.closed
+}
+
+// closeCursorsAtTxEnd marks the cursors that do not outlive the end of a transaction as closed. Cursors declared WITH
+// HOLD remain open when the transaction is committed and use conn from then on.
+func closeCursorsAtTxEnd(cursors []*Cursor, committed bool, conn *Conn) {
+	for _, c := range cursors {
+		if committed && c.hold {
+			c.db = conn
+			continue
+		}
+		c.closed = true
+	}
+}
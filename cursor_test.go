@@ -0,0 +1,162 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		cursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{}, "select n from generate_series(1, $1::int) n", 10)
+		require.NoError(t, err)
+
+		rows, err := cursor.Fetch(ctx, 4)
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3, 4}, numbers)
+
+		moved, err := cursor.Move(ctx, 2)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, moved)
+
+		rows, err = cursor.FetchAll(ctx)
+		require.NoError(t, err)
+		numbers, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{7, 8, 9, 10}, numbers)
+
+		rows, err = cursor.Fetch(ctx, 4)
+		require.NoError(t, err)
+		numbers, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Empty(t, numbers)
+
+		require.NoError(t, cursor.Close(ctx))
+		require.True(t, cursor.IsClosed())
+
+		_, err = cursor.Fetch(ctx, 1)
+		require.ErrorIs(t, err, pgx.ErrCursorClosed)
+
+		require.NoError(t, tx.Commit(ctx))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestCursorScroll(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		cursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{Name: "scroll cursor", Scroll: true}, "select n from generate_series(1, 10) n")
+		require.NoError(t, err)
+		assert.Equal(t, `"scroll cursor"`, cursor.Name())
+
+		rows, err := cursor.FetchAbsolute(ctx, 5)
+		require.NoError(t, err)
+		n, err := pgx.CollectOneRow(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, n)
+
+		rows, err = cursor.FetchRelative(ctx, -2)
+		require.NoError(t, err)
+		n, err = pgx.CollectOneRow(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, n)
+
+		rows, err = cursor.Fetch(ctx, -2)
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{2, 1}, numbers)
+
+		_, err = cursor.MoveAbsolute(ctx, -1)
+		require.NoError(t, err)
+
+		rows, err = cursor.FetchRelative(ctx, 0)
+		require.NoError(t, err)
+		n, err = pgx.CollectOneRow(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.EqualValues(t, 10, n)
+
+		moved, err := cursor.MoveRelative(ctx, -3)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, moved)
+
+		rows, err = cursor.Fetch(ctx, 1)
+		require.NoError(t, err)
+		n, err = pgx.CollectOneRow(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.EqualValues(t, 8, n)
+
+		require.NoError(t, tx.Rollback(ctx))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestCursorClosedAtTxEnd(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+
+		cursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{}, "select 1")
+		require.NoError(t, err)
+
+		nestedTx, err := tx.Begin(ctx)
+		require.NoError(t, err)
+		nestedCursor, err := pgx.DeclareCursor(ctx, nestedTx, pgx.CursorOptions{}, "select 2")
+		require.NoError(t, err)
+		require.NoError(t, nestedTx.Rollback(ctx))
+		require.True(t, nestedCursor.IsClosed())
+		require.False(t, cursor.IsClosed())
+
+		holdCursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{Hold: true}, "select n from generate_series(1, 3) n")
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Commit(ctx))
+		require.True(t, cursor.IsClosed())
+		require.False(t, holdCursor.IsClosed())
+
+		_, err = cursor.Fetch(ctx, 1)
+		require.ErrorIs(t, err, pgx.ErrCursorClosed)
+
+		// A cursor declared WITH HOLD remains usable after commit.
+		rows, err := holdCursor.FetchAll(ctx)
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3}, numbers)
+
+		require.NoError(t, holdCursor.Close(ctx))
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,82 @@
+package pgx
+
+import (
+	"sync"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SharedDescriptionCache is a statement description cache used with QueryExecModeCacheDescribe that is safe for
+// concurrent use. It can be shared by multiple connections to the same database so a statement is only described once
+// for all of them. Set ConnConfig.DescriptionCache to use it. The connections must resolve names in the same way, i.e.
+// they must connect to the same database with the same search_path.
+//
+// When any connection using the cache receives an error for a query that indicates that the schema has changed, such
+// as an undefined column or a mismatch of the number of result columns, the description of that query is invalidated
+// for all connections. Other errors, such as constraint violations or invalid arguments, do not invalidate it.
+type SharedDescriptionCache struct {
+	mu    sync.Mutex
+	cache *stmtcache.LRUCache
+}
+
+// NewSharedDescriptionCache creates a new SharedDescriptionCache. capacity is the maximum number of statement
+// descriptions in the cache. The least recently used description is removed when the cache is full.
+func NewSharedDescriptionCache(capacity int) *SharedDescriptionCache {
+	return &SharedDescriptionCache{cache: stmtcache.NewLRUCache(capacity)}
+}
+
+// Get returns the statement description for sql. Returns nil if not found.
+func (c *SharedDescriptionCache) Get(sql string) *pgconn.StatementDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(sql)
+}
+
+// Put stores sd in the cache. Put panics if sd.SQL is "". Put does nothing if sd.SQL already exists in the cache.
+func (c *SharedDescriptionCache) Put(sd *pgconn.StatementDescription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Put(sd)
+}
+
+// Invalidate invalidates the statement description identified by sql. Does nothing if not found.
+func (c *SharedDescriptionCache) Invalidate(sql string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Invalidate(sql)
+}
+
+// InvalidateAll invalidates all statement descriptions.
+func (c *SharedDescriptionCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.InvalidateAll()
+}
+
+// HandleInvalidated returns a slice of all statement descriptions invalidated since the last call to
+// HandleInvalidated. It is called by the connections using the cache.
+func (c *SharedDescriptionCache) HandleInvalidated() []*pgconn.StatementDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.HandleInvalidated()
+}
+
+// Len returns the number of cached statement descriptions.
+func (c *SharedDescriptionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
+
+// Stats returns the hits, misses, and evictions of all connections using the cache.
+func (c *SharedDescriptionCache) Stats() StatementCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return statementCacheStats(c.cache.Stats())
+}
+
+// Cap returns the maximum number of cached statement descriptions.
+func (c *SharedDescriptionCache) Cap() int {
+	return c.cache.Cap()
+}
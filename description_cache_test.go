@@ -0,0 +1,117 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedDescriptionCacheConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	cache := pgx.NewSharedDescriptionCache(10)
+	require.Equal(t, 10, cache.Cap())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sql := "select " + strconv.Itoa(j%20)
+				if cache.Get(sql) == nil {
+					cache.Put(&pgconn.StatementDescription{SQL: sql})
+				}
+				if j%10 == i {
+					cache.Invalidate(sql)
+				}
+				cache.HandleInvalidated()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, cache.Len(), 10)
+
+	cache.InvalidateAll()
+	require.Equal(t, 0, cache.Len())
+}
+
+func TestConnSharedDescriptionCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	prepareCount := 0
+	tracer := &testTracer{
+		tracePrepareStart: func(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+			prepareCount++
+			return ctx
+		},
+	}
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+	config.DescriptionCache = pgx.NewSharedDescriptionCache(16)
+	config.Tracer = tracer
+
+	conn1 := mustConnect(t, config)
+	defer closeConn(t, conn1)
+	conn2 := mustConnect(t, config)
+	defer closeConn(t, conn2)
+
+	var n int32
+	err := conn1.QueryRow(ctx, "select $1::int4", 1).Scan(&n)
+	require.NoError(t, err)
+	require.Equal(t, 1, prepareCount)
+
+	err = conn2.QueryRow(ctx, "select $1::int4", 2).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.Equal(t, 1, prepareCount)
+
+	// Errors that are not caused by a schema change do not invalidate the description.
+	err = conn2.QueryRow(ctx, "select $1::int4", "not a number").Scan(&n)
+	require.Error(t, err)
+	_, err = conn2.Exec(ctx, "select 1 / $1::int4", 0)
+	require.Error(t, err)
+	require.Equal(t, 2, config.DescriptionCache.Len())
+
+	err = conn1.QueryRow(ctx, "select $1::int4", 3).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.Equal(t, 2, prepareCount)
+
+	// A schema change error on one connection invalidates the description for all connections.
+	for _, conn := range []*pgx.Conn{conn1, conn2} {
+		_, err = conn.Exec(ctx, "create temporary table shared_description_cache(a int4, b int4)", pgx.QueryExecModeExec)
+		require.NoError(t, err)
+	}
+
+	var a, b int32
+	err = conn1.QueryRow(ctx, "select * from shared_description_cache").Scan(&a, &b)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Equal(t, 3, prepareCount)
+
+	_, err = conn2.Exec(ctx, "alter table shared_description_cache drop column b", pgx.QueryExecModeExec)
+	require.NoError(t, err)
+	err = conn2.QueryRow(ctx, "select * from shared_description_cache").Scan(&a)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "08P01", pgErr.Code)
+
+	err = conn1.QueryRow(ctx, "select * from shared_description_cache").Scan(&a, &b)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Equal(t, 4, prepareCount)
+
+	ensureConnValid(t, conn1)
+	ensureConnValid(t, conn2)
+}
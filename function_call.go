@@ -0,0 +1,122 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// functionDescription describes a function that can be called with the fastpath function call interface.
+type functionDescription struct {
+	OID       uint32
+	ArgOIDs   []uint32
+	ResultOID uint32
+}
+
+// FunctionCall calls the function name using the PostgreSQL fastpath function call interface and scans the returned
+// value into result. This avoids parsing and planning a query for each call. It is primarily useful for calling simple
+// functions such as loread and lowrite at a high rate.
+//
+// name may include an argument type list (e.g. "lo_lseek64(integer,bigint,integer)") to select between overloaded
+// functions. The function OID and argument types are looked up the first time a name is used and cached on the
+// connection. args are encoded with the connection's type map according to the argument types of the function. result
+// may be nil to discard the returned value.
+//
+// The fastpath interface does not support functions returning sets or with variadic arguments.
+//
+// The call is reported to the QueryTracer as the equivalent "select name($1, ...)" query.
+func (c *Conn) FunctionCall(ctx context.Context, name string, result any, args ...any) error {
+	if c.queryTracer != nil {
+		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: functionCallSQL(name, len(args)), Args: args})
+	}
+
+	err := c.functionCall(ctx, name, result, args)
+
+	if c.queryTracer != nil {
+		c.queryTracer.TraceQueryEnd(ctx, c, TraceQueryEndData{Err: err})
+	}
+
+	return err
+}
+
+func (c *Conn) functionCall(ctx context.Context, name string, result any, args []any) error {
+	fd, err := c.getFunctionDescription(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != len(fd.ArgOIDs) {
+		return fmt.Errorf("function %s expects %d arguments, got %d", name, len(fd.ArgOIDs), len(args))
+	}
+
+	sd := &pgconn.StatementDescription{
+		ParamOIDs: fd.ArgOIDs,
+		Fields:    []pgconn.FieldDescription{{DataTypeOID: fd.ResultOID}},
+	}
+	err = c.eqb.Build(c.typeMap, sd, args)
+	if err != nil {
+		return err
+	}
+
+	resultFormat := c.eqb.ResultFormats[0]
+	buf, err := c.pgConn.FunctionCall(ctx, fd.OID, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormat)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return c.typeMap.Scan(fd.ResultOID, resultFormat, buf, result)
+}
+
+// functionCallSQL returns the query equivalent to calling the function name with argCount arguments.
+func functionCallSQL(name string, argCount int) string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("select ")
+	sb.WriteString(name)
+	sb.WriteByte('(')
+	for i := 0; i < argCount; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('$')
+		sb.WriteString(strconv.Itoa(i + 1))
+	}
+	sb.WriteByte(')')
+
+	return sb.String()
+}
+
+func (c *Conn) getFunctionDescription(ctx context.Context, name string) (*functionDescription, error) {
+	if fd, ok := c.functionDescriptions[name]; ok {
+		return fd, nil
+	}
+
+	// regproc only accepts a name and fails for overloaded functions. regprocedure requires the argument types.
+	sql := "select oid, proargtypes::oid[], prorettype from pg_proc where oid = $1::text::regproc"
+	if strings.Contains(name, "(") {
+		sql = "select oid, proargtypes::oid[], prorettype from pg_proc where oid = $1::text::regprocedure"
+	}
+
+	fd := &functionDescription{}
+	err := c.QueryRow(ctx, sql, name).Scan(&fd.OID, &fd.ArgOIDs, &fd.ResultOID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.functionDescriptions == nil {
+		c.functionDescriptions = make(map[string]*functionDescription)
+	}
+	c.functionDescriptions[name] = fd
+
+	return fd, nil
+}
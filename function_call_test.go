@@ -0,0 +1,136 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support the fastpath function call interface")
+
+		var n int32
+		err := conn.FunctionCall(ctx, "int4pl(integer,integer)", &n, 1, 2)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+
+		var s string
+		err = conn.FunctionCall(ctx, "upper(text)", &s, "foo")
+		require.NoError(t, err)
+		require.Equal(t, "FOO", s)
+
+		var ns *string
+		err = conn.FunctionCall(ctx, "upper(text)", &ns, nil)
+		require.NoError(t, err)
+		require.Nil(t, ns)
+
+		err = conn.FunctionCall(ctx, "int4pl(integer,integer)", &n, 1)
+		require.ErrorContains(t, err, "expects 2 arguments")
+
+		err = conn.FunctionCall(ctx, "int4pl(integer,integer)", &n, 2147483647, 1)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22003", pgErr.Code)
+
+		err = conn.FunctionCall(ctx, "no_such_function_for_pgx_test", nil)
+		require.Error(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnFunctionCallTraced(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support the fastpath function call interface")
+
+		// Look up the function before tracing so only the function call itself is traced.
+		var n int32
+		err := conn.FunctionCall(ctx, "int4pl(integer,integer)", &n, 1, 2)
+		require.NoError(t, err)
+
+		traceQueryStartCalled := false
+		tracer.traceQueryStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+			traceQueryStartCalled = true
+			require.Equal(t, `select int4pl($1, $2)`, data.SQL)
+			require.Equal(t, []any{3, 4}, data.Args)
+			return context.WithValue(ctx, ctxKey("fromTraceQueryStart"), "foo")
+		}
+
+		traceQueryEndCalled := false
+		tracer.traceQueryEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+			traceQueryEndCalled = true
+			require.Equal(t, "foo", ctx.Value(ctxKey("fromTraceQueryStart")))
+			require.NoError(t, data.Err)
+		}
+		defer func() {
+			tracer.traceQueryStart = nil
+			tracer.traceQueryEnd = nil
+		}()
+
+		err = conn.FunctionCall(ctx, "int4pl(integer,integer)", &n, 3, 4)
+		require.NoError(t, err)
+		require.EqualValues(t, 7, n)
+		require.True(t, traceQueryStartCalled)
+		require.True(t, traceQueryEndCalled)
+	})
+}
+
+func TestConnFunctionCallDeallocateAllClearsFunctionDescriptions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support the fastpath function call interface")
+
+		createFunction := `create function pg_temp.pgx_function_call_test(integer) returns integer language sql as 'select $1 + 1'`
+		_, err := conn.Exec(ctx, createFunction)
+		require.NoError(t, err)
+
+		var n int32
+		err = conn.FunctionCall(ctx, "pg_temp.pgx_function_call_test(integer)", &n, 1)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+
+		// Recreating the function changes its OID.
+		_, err = conn.Exec(ctx, `drop function pg_temp.pgx_function_call_test(integer)`)
+		require.NoError(t, err)
+		_, err = conn.Exec(ctx, createFunction)
+		require.NoError(t, err)
+
+		err = conn.DeallocateAll(ctx)
+		require.NoError(t, err)
+
+		err = conn.FunctionCall(ctx, "pg_temp.pgx_function_call_test(integer)", &n, 2)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+
+		ensureConnValid(t, conn)
+	})
+}
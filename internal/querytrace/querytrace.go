@@ -0,0 +1,41 @@
+// Package querytrace passes data about the execution of a query between pgx and the tracers of this module through the
+// context of the query.
+package querytrace
+
+import "context"
+
+type ctxKey int
+
+const (
+	_ ctxKey = iota
+	sentQueryCtxKey
+	skipQueryRewritersCtxKey
+)
+
+// Query is the SQL and arguments of a query after the query rewriters were applied.
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+// WithSentQuery returns a context that makes pgx record the SQL and arguments sent to the server in q. q may be nil to
+// stop recording.
+func WithSentQuery(ctx context.Context, q *Query) context.Context {
+	return context.WithValue(ctx, sentQueryCtxKey, q)
+}
+
+// SentQuery returns the Query set with WithSentQuery or nil.
+func SentQuery(ctx context.Context) *Query {
+	q, _ := ctx.Value(sentQueryCtxKey).(*Query)
+	return q
+}
+
+// WithoutQueryRewriters returns a context that makes pgx skip ConnConfig.QueryRewriters.
+func WithoutQueryRewriters(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipQueryRewritersCtxKey, true)
+}
+
+// SkipQueryRewriters reports whether ctx was returned by WithoutQueryRewriters.
+func SkipQueryRewriters(ctx context.Context) bool {
+	return ctx.Value(skipQueryRewritersCtxKey) != nil
+}
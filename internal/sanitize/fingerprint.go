@@ -0,0 +1,201 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type fingerprintToken struct {
+	text  string
+	space bool // whitespace or a comment preceded the token in the source.
+}
+
+// Fingerprint returns a normalized form of sql that is the same for queries that only differ in literal values,
+// placeholder numbers, comments, whitespace, trailing semicolons, or the case of keywords and unquoted identifiers.
+//
+// String, bit string, dollar-quoted, and numeric literals and placeholders are replaced with ?. A parenthesized list
+// of only literals and placeholders following IN is replaced with (...). Quoted identifiers are kept as is.
+func Fingerprint(sql string) string {
+	l := &sqlLexer{src: sql}
+	var tokens []fingerprintToken
+	space := false
+
+	emit := func(text string) {
+		tokens = append(tokens, fingerprintToken{text: text, space: space})
+		space = false
+	}
+
+	for l.pos < len(l.src) {
+		start := l.pos
+		r, width := utf8.DecodeRuneInString(l.src[l.pos:])
+		nextRune, _ := utf8.DecodeRuneInString(l.src[l.pos+width:])
+
+		switch {
+		case unicode.IsSpace(r):
+			l.pos += width
+			space = true
+		case r == '-' && nextRune == '-':
+			l.pos += 2
+			oneLineCommentState(l)
+			space = true
+		case r == '/' && nextRune == '*':
+			l.pos += 2
+			multilineCommentState(l)
+			space = true
+		case r == '\'':
+			l.pos += width
+			singleQuoteState(l)
+			emit("?")
+		case (r == 'e' || r == 'E') && nextRune == '\'':
+			l.pos += 2
+			escapeStringState(l)
+			emit("?")
+		case strings.ContainsRune("bBxXnN", r) && nextRune == '\'':
+			l.pos += 2
+			singleQuoteState(l)
+			emit("?")
+		case r == '"':
+			l.pos += width
+			doubleQuoteState(l)
+			emit(l.src[start:l.pos])
+		case r == '$' && '0' <= nextRune && nextRune <= '9':
+			l.pos += width
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.pos++
+			}
+			emit("?")
+		case r == '$':
+			if end := dollarQuoteEnd(l.src, l.pos); end > 0 {
+				l.pos = end
+				emit("?")
+			} else {
+				l.pos += width
+				emit("$")
+			}
+		case ('0' <= r && r <= '9') || (r == '.' && '0' <= nextRune && nextRune <= '9'):
+			l.pos = numberEnd(l.src, l.pos)
+			emit("?")
+		case isIdentStart(r):
+			l.pos += width
+			for l.pos < len(l.src) {
+				r, width := utf8.DecodeRuneInString(l.src[l.pos:])
+				if !isIdentStart(r) && !('0' <= r && r <= '9') && r != '$' {
+					break
+				}
+				l.pos += width
+			}
+			emit(strings.ToLower(l.src[start:l.pos]))
+		case strings.ContainsRune(operatorChars, r):
+			l.pos += width
+			for l.pos < len(l.src) && strings.IndexByte(operatorChars, l.src[l.pos]) >= 0 &&
+				!strings.HasPrefix(l.src[l.pos:], "--") && !strings.HasPrefix(l.src[l.pos:], "/*") {
+				l.pos++
+			}
+			emit(l.src[start:l.pos])
+		default:
+			l.pos += width
+			emit(l.src[start:l.pos])
+		}
+	}
+
+	for len(tokens) > 0 && tokens[len(tokens)-1].text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	tokens = collapseInLists(tokens)
+
+	var sb strings.Builder
+	for i, tok := range tokens {
+		if i > 0 && fingerprintSpace(tokens[i-1], tok) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.text)
+	}
+
+	return sb.String()
+}
+
+const operatorChars = "+-*/<>=~!@#%^&|`?"
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || (r >= utf8.RuneSelf && r != utf8.RuneError)
+}
+
+// numberEnd returns the position after the numeric literal starting at pos. It accepts decimal, exponent, and
+// non-decimal integer forms such as 0x1F and 1_000.
+func numberEnd(src string, pos int) int {
+	for pos < len(src) {
+		b := src[pos]
+		switch {
+		case isDigit(b) || b == '.' || b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z'):
+			pos++
+			if (b == 'e' || b == 'E') && pos < len(src) && (src[pos] == '+' || src[pos] == '-') {
+				pos++
+			}
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// dollarQuoteEnd returns the position after the dollar-quoted string starting at pos or 0 if there is none.
+func dollarQuoteEnd(src string, pos int) int {
+	tagEnd := strings.IndexByte(src[pos+1:], '$')
+	if tagEnd < 0 {
+		return 0
+	}
+	tag := src[pos : pos+1+tagEnd+1]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !isIdentStart(r) && !('0' <= r && r <= '9') {
+			return 0
+		}
+	}
+
+	end := strings.Index(src[pos+len(tag):], tag)
+	if end < 0 {
+		return len(src)
+	}
+	return pos + len(tag) + end + len(tag)
+}
+
+// collapseInLists replaces the values of IN (?, ?, ...) with ... so lists of any length have the same fingerprint.
+func collapseInLists(tokens []fingerprintToken) []fingerprintToken {
+	result := tokens[:0:0]
+	for i := 0; i < len(tokens); i++ {
+		result = append(result, tokens[i])
+		if tokens[i].text != "in" || i+2 >= len(tokens) || tokens[i+1].text != "(" || tokens[i+2].text != "?" {
+			continue
+		}
+
+		j := i + 3
+		for j+1 < len(tokens) && tokens[j].text == "," && tokens[j+1].text == "?" {
+			j += 2
+		}
+		if j < len(tokens) && tokens[j].text == ")" {
+			result = append(result, tokens[i+1], fingerprintToken{text: "..."}, tokens[j])
+			i = j
+		}
+	}
+	return result
+}
+
+// fingerprintSpace reports whether a space separates prev and tok in a fingerprint.
+func fingerprintSpace(prev, tok fingerprintToken) bool {
+	switch tok.text {
+	case ",", ")", "]", ";", ".", ":":
+		return false
+	case "(", "[":
+		return tok.space
+	}
+	switch prev.text {
+	case "(", "[", ".", ":":
+		return false
+	}
+	return true
+}
@@ -217,3 +217,30 @@ func TestQuerySanitize(t *testing.T) {
 		}
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{sql: "select 42", expected: "select ?"},
+		{sql: "SELECT  *\n  FROM users WHERE id = $1;", expected: "select * from users where id = ?"},
+		{sql: "select * from users where id=$2", expected: "select * from users where id = ?"},
+		{sql: "select 'foo''bar', E'a\\'b', B'101', X'1F', $$it's$$, $tag$x$$y$tag$", expected: "select ?, ?, ?, ?, ?, ?"},
+		{sql: "select 1.5, .5, 1e-10, 0x1F, 1_000 from t1", expected: "select ?, ?, ?, ?, ? from t1"},
+		{sql: "select \"Mixed Case\".\"ID\" from \"Mixed Case\"", expected: `select "Mixed Case"."ID" from "Mixed Case"`},
+		{sql: "select /* comment /* nested */ */ 1 -- trailing\n", expected: "select ?"},
+		{sql: "select * from t where id in (1, 2, 3)", expected: "select * from t where id in (...)"},
+		{sql: "select * from t where id IN ($1,$2) and x not in ('a')", expected: "select * from t where id in (...) and x not in (...)"},
+		{sql: "select * from t where id in (select id from u)", expected: "select * from t where id in (select id from u)"},
+		{sql: "select count(*), coalesce(a, 0) from t", expected: "select count(*), coalesce(a, ?) from t"},
+		{sql: "select $1::int4, a[1], x->>'y'", expected: "select ?::int4, a[?], x ->> ?"},
+	}
+
+	for i, tt := range tests {
+		actual := sanitize.Fingerprint(tt.sql)
+		if tt.expected != actual {
+			t.Errorf("%d. expected %s, but got %s", i, tt.expected, actual)
+		}
+	}
+}
@@ -2,24 +2,72 @@ package stmtcache
 
 import (
 	"container/list"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// Options are the options of an LRUCache.
+type Options struct {
+	// Capacity is the maximum number of statement descriptions in the cache.
+	Capacity int
+
+	// MaxBytes is the maximum total length of the SQL of the statement descriptions in the cache. If zero, there is no
+	// limit. A statement description whose SQL is longer than MaxBytes is still cached as the only entry.
+	MaxBytes int
+
+	// TTL is the duration after which a statement description that was put in the cache expires. Expired statement
+	// descriptions are removed by HandleInvalidated, not by Get, so that the caller can deallocate them before they are
+	// looked up again. If zero, statement descriptions do not expire.
+	TTL time.Duration
+
+	// OnEvict is called with each statement description that is removed from the cache because the cache is full or
+	// because it expired. It is not called for statement descriptions that are invalidated.
+	OnEvict func(sd *pgconn.StatementDescription)
+}
+
+// Stats are the statistics of a cache.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
 // LRUCache implements Cache with a Least Recently Used (LRU) cache.
 type LRUCache struct {
 	cap          int
+	maxBytes     int
+	ttl          time.Duration
+	onEvict      func(sd *pgconn.StatementDescription)
+	bytes        int
 	m            map[string]*list.Element
 	l            *list.List
 	invalidStmts []*pgconn.StatementDescription
+	stats        Stats
+
+	// nextExpiry is the earliest time a statement description in the cache expires. It is zero if none can expire.
+	nextExpiry time.Time
+}
+
+type lruEntry struct {
+	sd        *pgconn.StatementDescription
+	expiresAt time.Time
 }
 
 // NewLRUCache creates a new LRUCache. cap is the maximum size of the cache.
 func NewLRUCache(cap int) *LRUCache {
+	return NewLRUCacheWithOptions(Options{Capacity: cap})
+}
+
+// NewLRUCacheWithOptions creates a new LRUCache configured by opts.
+func NewLRUCacheWithOptions(opts Options) *LRUCache {
 	return &LRUCache{
-		cap: cap,
-		m:   make(map[string]*list.Element),
-		l:   list.New(),
+		cap:      opts.Capacity,
+		maxBytes: opts.MaxBytes,
+		ttl:      opts.TTL,
+		onEvict:  opts.OnEvict,
+		m:        make(map[string]*list.Element),
+		l:        list.New(),
 	}
 }
 
@@ -27,11 +75,12 @@ func NewLRUCache(cap int) *LRUCache {
 func (c *LRUCache) Get(key string) *pgconn.StatementDescription {
 	if el, ok := c.m[key]; ok {
 		c.l.MoveToFront(el)
-		return el.Value.(*pgconn.StatementDescription)
+		c.stats.Hits++
+		return el.Value.(*lruEntry).sd
 	}
 
+	c.stats.Misses++
 	return nil
-
 }
 
 // Put stores sd in the cache. Put panics if sd.SQL is "". Put does nothing if sd.SQL already exists in the cache or
@@ -52,20 +101,26 @@ func (c *LRUCache) Put(sd *pgconn.StatementDescription) {
 		}
 	}
 
-	if c.l.Len() == c.cap {
-		c.invalidateOldest()
+	for c.l.Len() > 0 && (c.l.Len() >= c.cap || (c.maxBytes > 0 && c.bytes+len(sd.SQL) > c.maxBytes)) {
+		c.evict(c.l.Back())
 	}
 
-	el := c.l.PushFront(sd)
+	entry := &lruEntry{sd: sd}
+	if c.ttl > 0 {
+		entry.expiresAt = time.Now().Add(c.ttl)
+		if c.nextExpiry.IsZero() {
+			c.nextExpiry = entry.expiresAt
+		}
+	}
+	el := c.l.PushFront(entry)
 	c.m[sd.SQL] = el
+	c.bytes += len(sd.SQL)
 }
 
 // Invalidate invalidates statement description identified by sql. Does nothing if not found.
 func (c *LRUCache) Invalidate(sql string) {
 	if el, ok := c.m[sql]; ok {
-		delete(c.m, sql)
-		c.invalidStmts = append(c.invalidStmts, el.Value.(*pgconn.StatementDescription))
-		c.l.Remove(el)
+		c.invalidate(el)
 	}
 }
 
@@ -73,17 +128,21 @@ func (c *LRUCache) Invalidate(sql string) {
 func (c *LRUCache) InvalidateAll() {
 	el := c.l.Front()
 	for el != nil {
-		c.invalidStmts = append(c.invalidStmts, el.Value.(*pgconn.StatementDescription))
+		c.invalidStmts = append(c.invalidStmts, el.Value.(*lruEntry).sd)
 		el = el.Next()
 	}
 
 	c.m = make(map[string]*list.Element)
 	c.l = list.New()
+	c.bytes = 0
+	c.nextExpiry = time.Time{}
 }
 
 // HandleInvalidated returns a slice of all statement descriptions invalidated since the last call to HandleInvalidated.
-// Typically, the caller will then deallocate them.
+// Expired statement descriptions are evicted first and are included. Typically, the caller will then deallocate them.
 func (c *LRUCache) HandleInvalidated() []*pgconn.StatementDescription {
+	c.evictExpired()
+
 	invalidStmts := c.invalidStmts
 	c.invalidStmts = nil
 	return invalidStmts
@@ -99,10 +158,56 @@ func (c *LRUCache) Cap() int {
 	return c.cap
 }
 
-func (c *LRUCache) invalidateOldest() {
-	oldest := c.l.Back()
-	sd := oldest.Value.(*pgconn.StatementDescription)
+// Bytes returns the total length of the SQL of the cached statement descriptions.
+func (c *LRUCache) Bytes() int {
+	return c.bytes
+}
+
+// Stats returns the number of hits, misses, and evictions since the cache was created.
+func (c *LRUCache) Stats() Stats {
+	return c.stats
+}
+
+func (c *LRUCache) invalidate(el *list.Element) {
+	sd := el.Value.(*lruEntry).sd
 	c.invalidStmts = append(c.invalidStmts, sd)
 	delete(c.m, sd.SQL)
-	c.l.Remove(oldest)
+	c.l.Remove(el)
+	c.bytes -= len(sd.SQL)
+}
+
+// evictExpired evicts the expired statement descriptions. The cache is only scanned when one may have expired.
+func (c *LRUCache) evictExpired() {
+	if c.nextExpiry.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	if now.Before(c.nextExpiry) {
+		return
+	}
+
+	c.nextExpiry = time.Time{}
+	el := c.l.Front()
+	for el != nil {
+		next := el.Next()
+		expiresAt := el.Value.(*lruEntry).expiresAt
+		if now.Before(expiresAt) {
+			if c.nextExpiry.IsZero() || expiresAt.Before(c.nextExpiry) {
+				c.nextExpiry = expiresAt
+			}
+		} else {
+			c.evict(el)
+		}
+		el = next
+	}
+}
+
+// evict invalidates el because the cache is full or el expired.
+func (c *LRUCache) evict(el *list.Element) {
+	c.invalidate(el)
+	c.stats.Evictions++
+	if c.onEvict != nil {
+		c.onEvict(el.Value.(*lruEntry).sd)
+	}
 }
@@ -0,0 +1,89 @@
+package stmtcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCacheCapacity(t *testing.T) {
+	var evicted []string
+	c := stmtcache.NewLRUCacheWithOptions(stmtcache.Options{
+		Capacity: 2,
+		OnEvict:  func(sd *pgconn.StatementDescription) { evicted = append(evicted, sd.SQL) },
+	})
+
+	c.Put(&pgconn.StatementDescription{SQL: "a"})
+	c.Put(&pgconn.StatementDescription{SQL: "b"})
+	require.NotNil(t, c.Get("a"))
+	c.Put(&pgconn.StatementDescription{SQL: "c"})
+
+	require.Equal(t, 2, c.Len())
+	require.Nil(t, c.Get("b"))
+	require.Equal(t, []string{"b"}, evicted)
+	require.Equal(t, stmtcache.Stats{Hits: 1, Misses: 1, Evictions: 1}, c.Stats())
+
+	invalidated := c.HandleInvalidated()
+	require.Len(t, invalidated, 1)
+	require.Equal(t, "b", invalidated[0].SQL)
+
+	c.Invalidate("a")
+	require.Equal(t, []string{"b"}, evicted)
+	require.EqualValues(t, 1, c.Stats().Evictions)
+}
+
+func TestLRUCacheMaxBytes(t *testing.T) {
+	c := stmtcache.NewLRUCacheWithOptions(stmtcache.Options{Capacity: 10, MaxBytes: 10})
+
+	c.Put(&pgconn.StatementDescription{SQL: "1234"})
+	c.Put(&pgconn.StatementDescription{SQL: "5678"})
+	require.Equal(t, 8, c.Bytes())
+
+	c.Put(&pgconn.StatementDescription{SQL: "abcd"})
+	require.Equal(t, 2, c.Len())
+	require.Equal(t, 8, c.Bytes())
+	require.Nil(t, c.Get("1234"))
+
+	c.Put(&pgconn.StatementDescription{SQL: "this is longer than max bytes"})
+	require.Equal(t, 1, c.Len())
+	require.NotNil(t, c.Get("this is longer than max bytes"))
+
+	c.InvalidateAll()
+	require.Equal(t, 0, c.Bytes())
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	var evicted []string
+	c := stmtcache.NewLRUCacheWithOptions(stmtcache.Options{
+		Capacity: 10,
+		TTL:      50 * time.Millisecond,
+		OnEvict:  func(sd *pgconn.StatementDescription) { evicted = append(evicted, sd.SQL) },
+	})
+
+	c.Put(&pgconn.StatementDescription{SQL: "a"})
+	require.NotNil(t, c.Get("a"))
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Expired statement descriptions are only removed by HandleInvalidated so they can be deallocated before they are
+	// looked up again.
+	require.NotNil(t, c.Get("a"))
+	require.Empty(t, evicted)
+
+	c.Put(&pgconn.StatementDescription{SQL: "b"})
+	invalidated := c.HandleInvalidated()
+	require.Len(t, invalidated, 1)
+	require.Equal(t, "a", invalidated[0].SQL)
+	require.Equal(t, []string{"a"}, evicted)
+	require.Equal(t, 1, c.Len())
+	require.Nil(t, c.Get("a"))
+	require.NotNil(t, c.Get("b"))
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Len(t, c.HandleInvalidated(), 1)
+	require.Equal(t, 0, c.Len())
+}
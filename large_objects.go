@@ -12,6 +12,10 @@ import (
 // For more details see: http://www.postgresql.org/docs/current/static/largeobjects.html
 type LargeObjects struct {
 	tx Tx
+
+	// UseFunctionCall makes large objects opened with Open read and write data with the fastpath function call
+	// interface (see Conn.FunctionCall) instead of queries. This avoids the overhead of parsing each read and write.
+	UseFunctionCall bool
 }
 
 type LargeObjectMode int32
@@ -35,7 +39,7 @@ func (o *LargeObjects) Open(ctx context.Context, oid uint32, mode LargeObjectMod
 	if err != nil {
 		return nil, err
 	}
-	return &LargeObject{fd: fd, tx: o.tx, ctx: ctx}, nil
+	return &LargeObject{fd: fd, tx: o.tx, ctx: ctx, useFunctionCall: o.UseFunctionCall}, nil
 }
 
 // Unlink removes a large object from the database.
@@ -64,12 +68,19 @@ type LargeObject struct {
 	ctx context.Context
 	tx  Tx
 	fd  int32
+
+	useFunctionCall bool
 }
 
 // Write writes p to the large object and returns the number of bytes written and an error if not all of p was written.
 func (o *LargeObject) Write(p []byte) (int, error) {
 	var n int
-	err := o.tx.QueryRow(o.ctx, "select lowrite($1, $2)", o.fd, p).Scan(&n)
+	var err error
+	if o.useFunctionCall {
+		err = o.tx.Conn().FunctionCall(o.ctx, "lowrite", &n, o.fd, p)
+	} else {
+		err = o.tx.QueryRow(o.ctx, "select lowrite($1, $2)", o.fd, p).Scan(&n)
+	}
 	if err != nil {
 		return n, err
 	}
@@ -84,7 +95,12 @@ func (o *LargeObject) Write(p []byte) (int, error) {
 // Read reads up to len(p) bytes into p returning the number of bytes read.
 func (o *LargeObject) Read(p []byte) (int, error) {
 	var res []byte
-	err := o.tx.QueryRow(o.ctx, "select loread($1, $2)", o.fd, len(p)).Scan(&res)
+	var err error
+	if o.useFunctionCall {
+		err = o.tx.Conn().FunctionCall(o.ctx, "loread", &res, o.fd, len(p))
+	} else {
+		err = o.tx.QueryRow(o.ctx, "select loread($1, $2)", o.fd, len(p)).Scan(&res)
+	}
 	copy(p, res)
 	if err != nil {
 		return len(res), err
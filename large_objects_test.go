@@ -61,8 +61,34 @@ func TestLargeObjectsSimpleProtocol(t *testing.T) {
 	testLargeObjects(t, ctx, tx)
 }
 
-func testLargeObjects(t *testing.T, ctx context.Context, tx pgx.Tx) {
+func TestLargeObjectsFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pgxtest.SkipCockroachDB(t, conn, "Server does support large objects")
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	lo := tx.LargeObjects()
+	lo.UseFunctionCall = true
+	testLargeObjectsWith(t, ctx, lo)
+}
+
+func testLargeObjects(t *testing.T, ctx context.Context, tx pgx.Tx) {
+	testLargeObjectsWith(t, ctx, tx.LargeObjects())
+}
+
+func testLargeObjectsWith(t *testing.T, ctx context.Context, lo pgx.LargeObjects) {
 
 	id, err := lo.Create(ctx, 0)
 	if err != nil {
@@ -0,0 +1,152 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/internal/anynil"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// MultiRows is an iterator over the result sets of a query that may contain multiple statements. Each result set is
+// read as a Rows with its own CommandTag. MultiRows must be closed before the connection can be used again.
+type MultiRows struct {
+	ctx  context.Context
+	conn *Conn
+
+	mrr  *pgconn.MultiResultReader
+	rows *baseRows
+
+	commandTag pgconn.CommandTag
+	err        error
+	closed     bool
+}
+
+// QueryMulti sends sql to the server with the simple protocol and returns a *MultiRows that iterates over the result
+// set of each statement in sql. args are interpolated into sql client-side with the same quoting and escaping as
+// QueryExecModeSimpleProtocol. Result values are decoded with the connection's type map in the text format.
+//
+// args may begin with a QueryRewriter. Other query options are not supported. ConnConfig.QueryRewriters are applied.
+//
+// If an error occurs while sending the query a nil *MultiRows and the error are returned. Errors that occur while
+// reading results are returned by the Rows of the failing statement and by MultiRows.Err and MultiRows.Close. The
+// server stops executing the remaining statements after a statement fails.
+func (c *Conn) QueryMulti(ctx context.Context, sql string, args ...any) (*MultiRows, error) {
+	if c.queryTracer != nil {
+		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
+	}
+
+	mr, err := c.queryMulti(ctx, sql, args)
+	if err != nil {
+		if c.queryTracer != nil {
+			c.queryTracer.TraceQueryEnd(ctx, c, TraceQueryEndData{Err: err})
+		}
+		return nil, err
+	}
+
+	return mr, nil
+}
+
+func (c *Conn) queryMulti(ctx context.Context, sql string, args []any) (*MultiRows, error) {
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 {
+		if queryRewriter, ok := args[0].(QueryRewriter); ok {
+			var err error
+			sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args[1:])
+			if err != nil {
+				return nil, fmt.Errorf("rewrite query failed: %w", err)
+			}
+		}
+	}
+
+	sql, args, err := c.applyQueryRewriters(ctx, QueryExecModeSimpleProtocol, sql, args)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite query failed: %w", err)
+	}
+
+	anynil.NormalizeSlice(args)
+	sql, err = c.sanitizeForSimpleQuery(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultiRows{ctx: ctx, conn: c, mrr: c.pgConn.Exec(ctx, sql)}, nil
+}
+
+// NextResult closes the Rows of the current result set and advances to the next result set. It returns true if a
+// result set is available. NextResult must be called before the first result set can be read.
+func (mr *MultiRows) NextResult() bool {
+	if mr.closed {
+		return false
+	}
+
+	if mr.rows != nil {
+		mr.rows.Close()
+		mr.commandTag = mr.rows.commandTag
+		if mr.rows.err != nil {
+			mr.err = mr.rows.err
+			mr.Close()
+			return false
+		}
+		mr.rows = nil
+	}
+
+	if !mr.mrr.NextResult() {
+		mr.Close()
+		return false
+	}
+
+	mr.rows = &baseRows{
+		ctx:          mr.ctx,
+		typeMap:      mr.conn.typeMap,
+		resultReader: mr.mrr.ResultReader(),
+		conn:         mr.conn,
+	}
+
+	return true
+}
+
+// Rows returns the Rows of the current result set. The Rows is closed by the next call to NextResult or Close.
+func (mr *MultiRows) Rows() Rows {
+	if mr.rows == nil {
+		return &baseRows{err: mr.err, closed: true}
+	}
+	return mr.rows
+}
+
+// Err returns the first error that occurred while reading the result sets.
+func (mr *MultiRows) Err() error {
+	return mr.err
+}
+
+// Close reads and discards any remaining result sets and returns the first error that occurred. It is safe to call
+// Close multiple times.
+func (mr *MultiRows) Close() error {
+	if mr.closed {
+		return mr.err
+	}
+	mr.closed = true
+
+	if mr.rows != nil {
+		mr.rows.Close()
+		mr.commandTag = mr.rows.commandTag
+		if mr.err == nil {
+			mr.err = mr.rows.err
+		}
+		mr.rows = nil
+	}
+
+	err := mr.mrr.Close()
+	if mr.err == nil {
+		mr.err = err
+	}
+
+	if mr.conn.queryTracer != nil {
+		mr.conn.queryTracer.TraceQueryEnd(mr.ctx, mr.conn, TraceQueryEndData{mr.commandTag, mr.err})
+	}
+
+	return mr.err
+}
@@ -0,0 +1,107 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryMulti(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, `create temporary table query_multi(n int4);
+insert into query_multi(n) select generate_series(1, $1);
+select n from query_multi order by n;
+select $2::text, $3::timestamptz`, 3, "it's", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		rows := mr.Rows()
+		require.False(t, rows.Next())
+		require.NoError(t, rows.Err())
+		rows.Close()
+		assert.Equal(t, "CREATE TABLE", rows.CommandTag().String())
+
+		require.True(t, mr.NextResult())
+		rows = mr.Rows()
+		rows.Close()
+		assert.Equal(t, "INSERT 0 3", rows.CommandTag().String())
+
+		require.True(t, mr.NextResult())
+		numbers, err := pgx.CollectRows(mr.Rows(), pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2, 3}, numbers)
+
+		require.True(t, mr.NextResult())
+		var s string
+		var ts time.Time
+		rows = mr.Rows()
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Scan(&s, &ts))
+		assert.Equal(t, "it's", s)
+		assert.True(t, ts.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+		require.False(t, mr.NextResult())
+		require.NoError(t, mr.Err())
+		require.NoError(t, mr.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryMultiError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, "select 1; select 1/0; select 3")
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		var n int32
+		rows := mr.Rows()
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Scan(&n))
+		assert.EqualValues(t, 1, n)
+
+		for mr.NextResult() {
+		}
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, mr.Err(), &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+		require.ErrorAs(t, mr.Close(), &pgErr)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryMultiCloseDiscardsRemainingResults(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, "select generate_series(1, 100); select 2")
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		require.True(t, mr.Rows().Next())
+		require.NoError(t, mr.Close())
+		require.False(t, mr.NextResult())
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5/internal/iobufpool"
@@ -99,6 +100,8 @@ type PgConn struct {
 	pipeline          Pipeline
 	contextWatcher    *ctxwatch.ContextWatcher
 	fieldDescriptions [16]FieldDescription
+	dataRowReader     pgproto3.DataRowReader
+	rowValues         [][]byte
 
 	cleanupDone chan struct{}
 }
@@ -539,6 +542,64 @@ func (pgConn *PgConn) peekMessage() (pgproto3.BackendMessage, error) {
 	return msg, nil
 }
 
+// receiveDataRow receives the next message. If it is a DataRow its values are appended to dst without decoding it into
+// a pgproto3.DataRow and ok is true. Any other message is decoded and left to be returned by the next call to
+// peekMessage or receiveMessage. The values are only valid until the next message is received.
+func (pgConn *PgConn) receiveDataRow(dst [][]byte) (values [][]byte, ok bool, err error) {
+	if pgConn.peekedMsg != nil || pgConn.bufferingReceive {
+		return dst, false, nil
+	}
+
+	msgType, msgBody, err := pgConn.frontend.ReceiveRaw()
+	if err != nil {
+		// Close on anything other than timeout error - everything else is fatal
+		var netErr net.Error
+		isNetErr := errors.As(err, &netErr)
+		if !(isNetErr && netErr.Timeout()) {
+			pgConn.asyncClose()
+		}
+
+		return dst, false, err
+	}
+
+	if msgType != 'D' {
+		msg, err := pgConn.frontend.DecodeMessage(msgType, msgBody)
+		if err != nil {
+			pgConn.asyncClose()
+			return dst, false, err
+		}
+		pgConn.peekedMsg = msg
+		return dst, false, nil
+	}
+
+	dr := &pgConn.dataRowReader
+	err = dr.Reset(msgBody)
+	if err == nil {
+		// Same rule as DataRow.Decode: reallocate when the buffer is too small or substantially too large so one row
+		// with many columns does not permanently pin memory.
+		fieldCount := dr.Len()
+		if cap(dst) < fieldCount || cap(dst)-fieldCount > 32 {
+			newCap := 32
+			if newCap < fieldCount {
+				newCap = fieldCount
+			}
+			dst = make([][]byte, 0, newCap)
+		}
+	}
+	for err == nil && dr.Next() {
+		dst = append(dst, dr.Value())
+	}
+	if err == nil {
+		err = dr.Err()
+	}
+	if err != nil {
+		pgConn.asyncClose()
+		return dst, false, err
+	}
+
+	return dst, true, nil
+}
+
 // receiveMessage receives a message without setting up context cancellation
 func (pgConn *PgConn) receiveMessage() (pgproto3.BackendMessage, error) {
 	msg, err := pgConn.peekMessage()
@@ -757,6 +818,19 @@ func (ct CommandTag) RowsAffected() int64 {
 	return n
 }
 
+// addRowsAffected returns a copy of ct with n added to the number of rows affected.
+func (ct CommandTag) addRowsAffected(n int64) CommandTag {
+	idx := len(ct.s)
+	for idx > 0 && ct.s[idx-1] >= '0' && ct.s[idx-1] <= '9' {
+		idx--
+	}
+	if idx == len(ct.s) {
+		return ct
+	}
+
+	return CommandTag{s: ct.s[:idx] + strconv.FormatInt(ct.RowsAffected()+n, 10)}
+}
+
 func (ct CommandTag) String() string {
 	return ct.s
 }
@@ -925,6 +999,71 @@ func (pgConn *PgConn) Deallocate(ctx context.Context, name string) error {
 	}
 }
 
+// FunctionCall calls the function identified by oid using the fastpath function call interface. This avoids the
+// overhead of parsing and planning a query. args are the function arguments and argFormats are their format codes.
+// argFormats may be nil to use text format for all arguments, a single element to use the same format for all
+// arguments, or one element per argument. resultFormat is the format code of the returned value.
+//
+// The result is nil if the function returned NULL.
+func (pgConn *PgConn) FunctionCall(ctx context.Context, oid uint32, args [][]byte, argFormats []int16, resultFormat int16) ([]byte, error) {
+	if err := pgConn.lock(); err != nil {
+		return nil, err
+	}
+	defer pgConn.unlock()
+
+	if ctx != context.Background() {
+		select {
+		case <-ctx.Done():
+			return nil, newContextAlreadyDoneError(ctx)
+		default:
+		}
+		pgConn.contextWatcher.Watch(ctx)
+		defer pgConn.contextWatcher.Unwatch()
+	}
+
+	argFormatCodes := make([]uint16, len(argFormats))
+	for i, fc := range argFormats {
+		argFormatCodes[i] = uint16(fc)
+	}
+
+	pgConn.frontend.Send(&pgproto3.FunctionCall{
+		Function:         oid,
+		ArgFormatCodes:   argFormatCodes,
+		Arguments:        args,
+		ResultFormatCode: uint16(resultFormat),
+	})
+	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		pgConn.asyncClose()
+		return nil, err
+	}
+
+	var result []byte
+	var pgErr error
+	for {
+		msg, err := pgConn.receiveMessage()
+		if err != nil {
+			pgConn.asyncClose()
+			return nil, normalizeTimeoutError(ctx, err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.FunctionCallResponse:
+			if msg.Result != nil {
+				result = make([]byte, len(msg.Result))
+				copy(result, msg.Result)
+			}
+		case *pgproto3.ErrorResponse:
+			pgErr = ErrorResponseToPgError(msg)
+		case *pgproto3.ReadyForQuery:
+			if pgErr != nil {
+				return nil, pgErr
+			}
+			return result, nil
+		}
+	}
+}
+
 // ErrorResponseToPgError converts a wire protocol error message to a *PgError.
 func ErrorResponseToPgError(msg *pgproto3.ErrorResponse) *PgError {
 	return &PgError{
@@ -1146,6 +1285,51 @@ func (pgConn *PgConn) ExecPrepared(ctx context.Context, stmtName string, paramVa
 	return result
 }
 
+// ExecParamsPortal executes a command like ExecParams, but binds it to the portal portalName and fetches the results in
+// pages of at most maxRows rows. When the server suspends the portal after maxRows rows, the ResultReader
+// automatically requests the next page as the rows are read. This allows reading arbitrarily large results with bounded
+// memory on both the client and the server. If maxRows is 0 all rows are fetched at once like ExecParams. The returned
+// CommandTag counts the rows of all pages.
+//
+// portalName may be empty to use the unnamed portal. Closing the ResultReader before all rows have been read closes
+// the portal without fetching the remaining rows. In that case the returned CommandTag is empty.
+//
+// ResultReader must be closed before PgConn can be used again.
+func (pgConn *PgConn) ExecParamsPortal(ctx context.Context, portalName, sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, maxRows uint32) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+	result.portalName = portalName
+	result.maxRows = maxRows
+
+	pgConn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
+	pgConn.frontend.SendBind(&pgproto3.Bind{DestinationPortal: portalName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffix(result)
+
+	return result
+}
+
+// ExecPreparedPortal executes a prepared statement like ExecPrepared, but binds it to the portal portalName and fetches
+// the results in pages of at most maxRows rows. See ExecParamsPortal for details.
+//
+// ResultReader must be closed before PgConn can be used again.
+func (pgConn *PgConn) ExecPreparedPortal(ctx context.Context, portalName, stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, maxRows uint32) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+	result.portalName = portalName
+	result.maxRows = maxRows
+
+	pgConn.frontend.SendBind(&pgproto3.Bind{DestinationPortal: portalName, PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffix(result)
+
+	return result
+}
+
 func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]byte) *ResultReader {
 	pgConn.resultReader = ResultReader{
 		pgConn: pgConn,
@@ -1182,9 +1366,14 @@ func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]by
 }
 
 func (pgConn *PgConn) execExtendedSuffix(result *ResultReader) {
-	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
-	pgConn.frontend.SendExecute(&pgproto3.Execute{})
-	pgConn.frontend.SendSync(&pgproto3.Sync{})
+	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P', Name: result.portalName})
+	pgConn.frontend.SendExecute(&pgproto3.Execute{Portal: result.portalName, MaxRows: result.maxRows})
+	if result.maxRows == 0 {
+		result.queueSync()
+	} else {
+		// The Sync is deferred until the portal is complete. Sync would close the portal if not in a transaction.
+		pgConn.frontend.Send(&pgproto3.Flush{})
+	}
 
 	err := pgConn.flushWithPotentialWriteReadDeadlock()
 	if err != nil {
@@ -1385,6 +1574,301 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 	}
 }
 
+// CopyBoth is a bidirectional copy session started by StartCopyBoth. CopyData messages can be sent and received
+// until either side ends the session.
+//
+// The methods of CopyBoth must not be called concurrently. Sending and receiving share the connection, and Send may
+// read from it in the background while flushing. A method called while another one is in progress returns an error. To
+// send messages while waiting for data, such as the status updates of a replication client, call Receive with a
+// context that has a deadline and call Send after it expires.
+type CopyBoth struct {
+	pgConn *PgConn
+	ctx    context.Context
+	busy   atomic.Bool
+
+	copyDoneSent     bool
+	copyDoneReceived bool
+
+	commandTag CommandTag
+	err        error
+	closed     bool
+}
+
+// StartCopyBoth executes sql which must start a bidirectional copy such as START_REPLICATION on a replication
+// connection. It returns a *CopyBoth once the server has responded with CopyBothResponse. If the server responds in
+// any other way the command is run to completion and an error is returned.
+//
+// Done or Fail must be called on the returned *CopyBoth to end the session before PgConn can be used again. ctx is in
+// effect for the entire life of the *CopyBoth.
+func (pgConn *PgConn) StartCopyBoth(ctx context.Context, sql string) (*CopyBoth, error) {
+	if err := pgConn.lock(); err != nil {
+		return nil, err
+	}
+
+	if ctx != context.Background() {
+		select {
+		case <-ctx.Done():
+			pgConn.unlock()
+			return nil, newContextAlreadyDoneError(ctx)
+		default:
+		}
+		pgConn.contextWatcher.Watch(ctx)
+	}
+
+	cb := &CopyBoth{pgConn: pgConn, ctx: ctx}
+
+	pgConn.frontend.SendQuery(&pgproto3.Query{String: sql})
+	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		return nil, cb.fail(err)
+	}
+
+	for {
+		msg, err := pgConn.receiveMessage()
+		if err != nil {
+			return nil, cb.fail(err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return cb, nil
+		case *pgproto3.CopyInResponse:
+			pgConn.frontend.Send(&pgproto3.CopyFail{Message: "expected CopyBothResponse"})
+			err := pgConn.flushWithPotentialWriteReadDeadlock()
+			if err != nil {
+				return nil, cb.fail(err)
+			}
+		case *pgproto3.ErrorResponse:
+			cb.err = ErrorResponseToPgError(msg)
+		case *pgproto3.ReadyForQuery:
+			cb.conclude()
+			if cb.err == nil {
+				cb.err = errors.New("sql did not start a copy both session")
+			}
+			return nil, cb.err
+		}
+	}
+}
+
+// Send sends data to the server in a CopyData message.
+func (cb *CopyBoth) Send(data []byte) error {
+	if err := cb.lock(); err != nil {
+		return err
+	}
+	defer cb.unlock()
+
+	if cb.closed {
+		return cb.closedError()
+	}
+	if cb.copyDoneSent {
+		return errors.New("cannot send data after CopyDone")
+	}
+
+	cb.pgConn.frontend.Send(&pgproto3.CopyData{Data: data})
+	err := cb.pgConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		return cb.fail(err)
+	}
+
+	return nil
+}
+
+// Receive returns the data of the next CopyData message from the server. The returned buffer is only valid until the
+// next call to Receive. io.EOF is returned when the server has ended its side of the session with CopyDone. Done must
+// still be called to end the session.
+//
+// ctx only applies to this call. If ctx is canceled or its deadline is exceeded before a message is received, the
+// context error is returned and the session remains usable. Canceling the context passed to StartCopyBoth still closes
+// the connection.
+//
+// If the server ends the session with an error, the error is returned and the session is closed.
+func (cb *CopyBoth) Receive(ctx context.Context) ([]byte, error) {
+	if err := cb.lock(); err != nil {
+		return nil, err
+	}
+	defer cb.unlock()
+
+	if cb.closed {
+		return nil, cb.closedError()
+	}
+	if cb.copyDoneReceived {
+		return nil, io.EOF
+	}
+
+	if ctx != context.Background() {
+		select {
+		case <-ctx.Done():
+			return nil, newContextAlreadyDoneError(ctx)
+		default:
+		}
+
+		receiveCtx, cancel := cb.watchReceiveContext(ctx)
+		defer func() {
+			cancel()
+			if !cb.closed {
+				cb.pgConn.contextWatcher.Unwatch()
+				cb.pgConn.contextWatcher.Watch(cb.ctx)
+			}
+		}()
+		ctx = receiveCtx
+	}
+
+	for {
+		msg, err := cb.pgConn.receiveMessage()
+		if err != nil {
+			// A timeout caused by ctx alone leaves the connection intact. Any partially received message is completed by the
+			// next receive.
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() != nil && cb.ctx.Err() == nil {
+				return nil, &pgconnError{
+					msg:         "receive message failed",
+					err:         normalizeTimeoutError(ctx, err),
+					safeToRetry: true,
+				}
+			}
+			return nil, cb.fail(err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			return msg.Data, nil
+		case *pgproto3.CopyDone:
+			cb.copyDoneReceived = true
+			return nil, io.EOF
+		case *pgproto3.ErrorResponse:
+			cb.err = ErrorResponseToPgError(msg)
+			cb.readUntilReadyForQuery()
+			return nil, cb.err
+		}
+	}
+}
+
+// watchReceiveContext replaces the watched session context with a context that is done when either ctx or the session
+// context is done.
+func (cb *CopyBoth) watchReceiveContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	receiveCtx, cancel := context.WithCancel(ctx)
+	if sessionDone := cb.ctx.Done(); sessionDone != nil {
+		go func() {
+			select {
+			case <-sessionDone:
+				cancel()
+			case <-receiveCtx.Done():
+			}
+		}()
+	}
+
+	cb.pgConn.contextWatcher.Unwatch()
+	cb.pgConn.contextWatcher.Watch(receiveCtx)
+
+	return receiveCtx, cancel
+}
+
+// Done ends the session by sending CopyDone to the server. Any CopyData messages received from the server after
+// CopyDone was sent are discarded. It returns the CommandTag of the last command completed by the server.
+func (cb *CopyBoth) Done() (CommandTag, error) {
+	if err := cb.lock(); err != nil {
+		return CommandTag{}, err
+	}
+	defer cb.unlock()
+
+	if cb.closed {
+		return cb.commandTag, cb.err
+	}
+
+	if !cb.copyDoneSent {
+		cb.pgConn.frontend.Send(&pgproto3.CopyDone{})
+		err := cb.pgConn.flushWithPotentialWriteReadDeadlock()
+		if err != nil {
+			return CommandTag{}, cb.fail(err)
+		}
+		cb.copyDoneSent = true
+	}
+
+	cb.readUntilReadyForQuery()
+	return cb.commandTag, cb.err
+}
+
+// Fail aborts the session by sending CopyFail with message to the server. The server responds with an error which is
+// returned.
+func (cb *CopyBoth) Fail(message string) error {
+	if err := cb.lock(); err != nil {
+		return err
+	}
+	defer cb.unlock()
+
+	if cb.closed {
+		return cb.err
+	}
+
+	// CopyFail is only meaningful while the server is still receiving data.
+	if !cb.copyDoneSent {
+		cb.pgConn.frontend.Send(&pgproto3.CopyFail{Message: message})
+		err := cb.pgConn.flushWithPotentialWriteReadDeadlock()
+		if err != nil {
+			return cb.fail(err)
+		}
+		cb.copyDoneSent = true
+	}
+
+	cb.readUntilReadyForQuery()
+	return cb.err
+}
+
+// lock marks cb as busy. It returns an error if another method of cb is in progress.
+func (cb *CopyBoth) lock() error {
+	if !cb.busy.CompareAndSwap(false, true) {
+		return errors.New("copy both session is busy")
+	}
+	return nil
+}
+
+func (cb *CopyBoth) unlock() {
+	cb.busy.Store(false)
+}
+
+func (cb *CopyBoth) readUntilReadyForQuery() {
+	for {
+		msg, err := cb.pgConn.receiveMessage()
+		if err != nil {
+			cb.fail(err)
+			return
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CommandComplete:
+			cb.commandTag = cb.pgConn.makeCommandTag(msg.CommandTag)
+		case *pgproto3.ErrorResponse:
+			cb.err = ErrorResponseToPgError(msg)
+		case *pgproto3.ReadyForQuery:
+			cb.conclude()
+			return
+		}
+	}
+}
+
+// conclude ends the session and returns the connection to normal use.
+func (cb *CopyBoth) conclude() {
+	cb.closed = true
+	cb.pgConn.contextWatcher.Unwatch()
+	cb.pgConn.unlock()
+}
+
+// fail closes the connection after a fatal error.
+func (cb *CopyBoth) fail(err error) error {
+	cb.err = normalizeTimeoutError(cb.ctx, err)
+	cb.closed = true
+	cb.pgConn.contextWatcher.Unwatch()
+	cb.pgConn.asyncClose()
+	return cb.err
+}
+
+func (cb *CopyBoth) closedError() error {
+	if cb.err != nil {
+		return cb.err
+	}
+	return errors.New("copy both session closed")
+}
+
 // MultiResultReader is a reader for a command that could return multiple results such as Exec or ExecBatch.
 type MultiResultReader struct {
 	pgConn   *PgConn
@@ -1412,11 +1896,7 @@ func (mrr *MultiResultReader) ReadAll() ([]*Result, error) {
 func (mrr *MultiResultReader) receiveMessage() (pgproto3.BackendMessage, error) {
 	msg, err := mrr.pgConn.receiveMessage()
 	if err != nil {
-		mrr.pgConn.contextWatcher.Unwatch()
-		mrr.err = normalizeTimeoutError(mrr.ctx, err)
-		mrr.closed = true
-		mrr.pgConn.asyncClose()
-		return nil, mrr.err
+		return nil, mrr.receiveFailed(err)
 	}
 
 	switch msg := msg.(type) {
@@ -1435,6 +1915,15 @@ func (mrr *MultiResultReader) receiveMessage() (pgproto3.BackendMessage, error)
 	return msg, nil
 }
 
+// receiveFailed closes mrr after receiving a message failed with err.
+func (mrr *MultiResultReader) receiveFailed(err error) error {
+	mrr.pgConn.contextWatcher.Unwatch()
+	mrr.err = normalizeTimeoutError(mrr.ctx, err)
+	mrr.closed = true
+	mrr.pgConn.asyncClose()
+	return mrr.err
+}
+
 // NextResult returns advances the MultiResultReader to the next result and returns true if a result is available.
 func (mrr *MultiResultReader) NextResult() bool {
 	for !mrr.closed && mrr.err == nil {
@@ -1500,6 +1989,15 @@ type ResultReader struct {
 	commandConcluded  bool
 	closed            bool
 	err               error
+
+	// portalName and maxRows are set when results are fetched in pages from a portal.
+	portalName string
+	maxRows    uint32
+	syncSent   bool
+
+	// suspendedRows is the number of rows received in the pages before the current one. The CommandComplete of a paged
+	// portal only counts the rows of the last page.
+	suspendedRows int64
 }
 
 // Result is the saved query response that is returned by calling Read on a ResultReader.
@@ -1537,6 +2035,22 @@ func (rr *ResultReader) Read() *Result {
 // NextRow advances the ResultReader to the next row and returns true if a row is available.
 func (rr *ResultReader) NextRow() bool {
 	for !rr.commandConcluded {
+		// DataRow messages are read directly into the reusable rowValues buffer to avoid decoding each one into a
+		// pgproto3.DataRow. Any other message is left for receiveMessage.
+		values, ok, err := rr.pgConn.receiveDataRow(rr.pgConn.rowValues[:0])
+		rr.pgConn.rowValues = values
+		if err != nil {
+			if rr.multiResultReader != nil {
+				err = rr.multiResultReader.receiveFailed(err)
+			}
+			rr.receiveFailed(err)
+			return false
+		}
+		if ok {
+			rr.rowValues = values
+			return true
+		}
+
 		msg, err := rr.receiveMessage()
 		if err != nil {
 			return false
@@ -1630,31 +2144,77 @@ func (rr *ResultReader) receiveMessage() (msg pgproto3.BackendMessage, err error
 	}
 
 	if err != nil {
-		err = normalizeTimeoutError(rr.ctx, err)
-		rr.concludeCommand(CommandTag{}, err)
-		rr.pgConn.contextWatcher.Unwatch()
-		rr.closed = true
-		if rr.multiResultReader == nil {
-			rr.pgConn.asyncClose()
-		}
-
-		return nil, rr.err
+		return nil, rr.receiveFailed(err)
 	}
 
 	switch msg := msg.(type) {
 	case *pgproto3.RowDescription:
 		rr.fieldDescriptions = rr.pgConn.convertRowDescription(rr.pgConn.fieldDescriptions[:], msg)
 	case *pgproto3.CommandComplete:
-		rr.concludeCommand(rr.pgConn.makeCommandTag(msg.CommandTag), nil)
+		commandTag := rr.pgConn.makeCommandTag(msg.CommandTag)
+		if rr.suspendedRows > 0 {
+			commandTag = commandTag.addRowsAffected(rr.suspendedRows)
+		}
+		rr.concludeCommand(commandTag, nil)
 	case *pgproto3.EmptyQueryResponse:
 		rr.concludeCommand(CommandTag{}, nil)
 	case *pgproto3.ErrorResponse:
 		rr.concludeCommand(CommandTag{}, ErrorResponseToPgError(msg))
+	case *pgproto3.PortalSuspended:
+		// A portal is only suspended after exactly maxRows rows.
+		rr.suspendedRows += int64(rr.maxRows)
+		if err := rr.continuePortal(); err != nil {
+			return nil, rr.receiveFailed(err)
+		}
+	}
+
+	// A paged portal defers the Sync until the command is concluded. The server also waits for a Sync after an error.
+	if rr.maxRows > 0 && rr.commandConcluded && !rr.syncSent {
+		rr.queueSync()
+		if err := rr.pgConn.flushWithPotentialWriteReadDeadlock(); err != nil {
+			return nil, rr.receiveFailed(err)
+		}
 	}
 
 	return msg, nil
 }
 
+// continuePortal requests the next page of rows from a suspended portal. If rr is being closed the portal is closed
+// instead of fetching the remaining rows.
+func (rr *ResultReader) continuePortal() error {
+	if rr.closed {
+		rr.concludeCommand(CommandTag{}, nil)
+		return nil
+	}
+
+	rr.pgConn.frontend.SendExecute(&pgproto3.Execute{Portal: rr.portalName, MaxRows: rr.maxRows})
+	rr.pgConn.frontend.Send(&pgproto3.Flush{})
+	return rr.pgConn.flushWithPotentialWriteReadDeadlock()
+}
+
+// queueSync queues a Sync message to end the command. A named or paged portal is explicitly closed first as it would
+// otherwise remain open until the end of the transaction.
+func (rr *ResultReader) queueSync() {
+	if rr.portalName != "" || rr.maxRows > 0 {
+		rr.pgConn.frontend.SendClose(&pgproto3.Close{ObjectType: 'P', Name: rr.portalName})
+	}
+	rr.pgConn.frontend.SendSync(&pgproto3.Sync{})
+	rr.syncSent = true
+}
+
+// receiveFailed closes rr after receiving a message failed with err.
+func (rr *ResultReader) receiveFailed(err error) error {
+	err = normalizeTimeoutError(rr.ctx, err)
+	rr.concludeCommand(CommandTag{}, err)
+	rr.pgConn.contextWatcher.Unwatch()
+	rr.closed = true
+	if rr.multiResultReader == nil {
+		rr.pgConn.asyncClose()
+	}
+
+	return rr.err
+}
+
 func (rr *ResultReader) concludeCommand(commandTag CommandTag, err error) {
 	// Keep the first error that is recorded. Store the error before checking if the command is already concluded to
 	// allow for receiving an error after CommandComplete but before ReadyForQuery.
@@ -1934,6 +2494,7 @@ type Pipeline struct {
 
 	expectedReadyForQueryCount int
 	pendingSync                bool
+	flushRequested             bool
 
 	err    error
 	closed bool
@@ -2026,6 +2587,19 @@ func (p *Pipeline) SendQueryPrepared(stmtName string, paramValues [][]byte, para
 	p.conn.frontend.SendExecute(&pgproto3.Execute{})
 }
 
+// SendFlushRequest queues a request for the server to send the results of all preceding requests without establishing
+// a synchronization point. Normally the server only sends results when it receives a Sync. After a flush request has
+// been flushed, GetResults may be used to read the results of the preceding requests before Sync is called. The caller
+// is responsible for not calling GetResults more times than there are results available.
+func (p *Pipeline) SendFlushRequest() {
+	if p.closed {
+		return
+	}
+	p.flushRequested = true
+
+	p.conn.frontend.Send(&pgproto3.Flush{})
+}
+
 // Flush flushes the queued requests without establishing a synchronization point.
 func (p *Pipeline) Flush() error {
 	if p.closed {
@@ -2067,6 +2641,7 @@ func (p *Pipeline) Sync() error {
 	}
 
 	p.pendingSync = false
+	p.flushRequested = false
 	p.expectedReadyForQueryCount++
 
 	return nil
@@ -2083,7 +2658,7 @@ func (p *Pipeline) GetResults() (results any, err error) {
 		return nil, errors.New("pipeline closed")
 	}
 
-	if p.expectedReadyForQueryCount == 0 {
+	if p.expectedReadyForQueryCount == 0 && !p.flushRequested {
 		return nil, nil
 	}
 
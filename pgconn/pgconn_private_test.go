@@ -1,9 +1,17 @@
 package pgconn
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/internal/pgmock"
+	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommandTag(t *testing.T) {
@@ -39,3 +47,90 @@ func TestCommandTag(t *testing.T) {
 		assert.Equalf(t, tt.isSelect, ct.Select(), "%d. %v", i, tt.commandTag)
 	}
 }
+
+func TestResultReaderRowValuesBufferShrinksAfterWideRow(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wideValues := make([][]byte, 200)
+	for i := range wideValues {
+		wideValues[i] = []byte("x")
+	}
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Parse{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Describe{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Execute{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("mock")},
+	}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: wideValues}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("y")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 2")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	script := &pgmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	conn, err := Connect(ctx, connStr)
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+
+	rr := conn.ExecParams(ctx, "mocked...", nil, nil, nil, nil)
+
+	require.True(t, rr.NextRow())
+	assert.Len(t, rr.Values(), 200)
+
+	require.True(t, rr.NextRow())
+	assert.Equal(t, [][]byte{[]byte("y")}, rr.Values())
+	assert.LessOrEqual(t, cap(conn.rowValues), 33)
+
+	require.False(t, rr.NextRow())
+	_, err = rr.Close()
+	require.NoError(t, err)
+
+	require.NoError(t, <-serverErrChan)
+}
+
+func TestCommandTagAddRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "SELECT 10", CommandTag{s: "SELECT 1"}.addRowsAffected(9).String())
+	assert.Equal(t, "SELECT 9", CommandTag{s: "SELECT 0"}.addRowsAffected(9).String())
+	assert.Equal(t, "INSERT 0 105", CommandTag{s: "INSERT 0 5"}.addRowsAffected(100).String())
+	assert.Equal(t, "CREATE TABLE", CommandTag{s: "CREATE TABLE"}.addRowsAffected(3).String())
+}
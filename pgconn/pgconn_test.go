@@ -744,6 +744,46 @@ func TestConnDeallocateNonExistantStatementSucceeds(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support the fastpath function call interface")
+	}
+
+	result := pgConn.ExecParams(ctx, "select 'int4pl(integer,integer)'::regprocedure::oid", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	oid, err := strconv.ParseUint(string(result.Rows[0][0]), 10, 32)
+	require.NoError(t, err)
+
+	buf, err := pgConn.FunctionCall(ctx, uint32(oid), [][]byte{[]byte("1"), []byte("2")}, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, "3", string(buf))
+
+	buf, err = pgConn.FunctionCall(ctx, uint32(oid), [][]byte{{0, 0, 0, 1}, {0, 0, 0, 2}}, []int16{1}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 3}, buf)
+
+	// int4pl is strict so a NULL argument returns NULL.
+	buf, err = pgConn.FunctionCall(ctx, uint32(oid), [][]byte{[]byte("1"), nil}, nil, 0)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+
+	_, err = pgConn.FunctionCall(ctx, uint32(oid), [][]byte{[]byte("2147483647"), []byte("1")}, nil, 0)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "22003", pgErr.Code)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnExec(t *testing.T) {
 	t.Parallel()
 
@@ -992,6 +1032,86 @@ func TestConnExecParams(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnExecParamsPortal(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParamsPortal(ctx, "", "select generate_series(1, $1::int)", [][]byte{[]byte("10")}, nil, nil, nil, 3).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, "SELECT 10", result.CommandTag.String())
+	require.Len(t, result.Rows, 10)
+	for i, row := range result.Rows {
+		assert.Equal(t, strconv.Itoa(i+1), string(row[0]))
+	}
+
+	// Close before reading all rows.
+	rr := pgConn.ExecParamsPortal(ctx, "p", "select generate_series(1, 10)", nil, nil, nil, nil, 3)
+	require.True(t, rr.NextRow())
+	assert.Equal(t, "1", string(rr.Values()[0]))
+	_, err = rr.Close()
+	require.NoError(t, err)
+
+	// The named portal must have been closed.
+	result = pgConn.ExecParamsPortal(ctx, "p", "select 1", nil, nil, nil, nil, 3).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, "SELECT 1", result.CommandTag.String())
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsPortalError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParamsPortal(ctx, "", "select 10 / (5 - n) from generate_series(1, 10) n", nil, nil, nil, nil, 2).Read()
+	require.Error(t, result.Err)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, result.Err, &pgErr)
+	assert.Equal(t, "22012", pgErr.Code)
+	assert.Len(t, result.Rows, 4)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecPreparedPortalInTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	_, err = pgConn.Prepare(ctx, "ps1", "select generate_series(1, $1::int)", nil)
+	require.NoError(t, err)
+
+	_, err = pgConn.Exec(ctx, "begin").ReadAll()
+	require.NoError(t, err)
+
+	result := pgConn.ExecPreparedPortal(ctx, "", "ps1", [][]byte{[]byte("5")}, nil, nil, 2).Read()
+	require.NoError(t, result.Err)
+	require.Len(t, result.Rows, 5)
+	assert.Equal(t, byte('T'), pgConn.TxStatus())
+
+	_, err = pgConn.Exec(ctx, "commit").ReadAll()
+	require.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnExecParamsDeferredError(t *testing.T) {
 	t.Parallel()
 
@@ -2117,6 +2237,34 @@ func TestConnCopyFromGzipReader(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnStartCopyBothWithoutCopyBothResponse(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	_, err = pgConn.StartCopyBoth(ctx, "copy (select 1) to stdout")
+	require.ErrorContains(t, err, "did not start a copy both session")
+	ensureConnValid(t, pgConn)
+
+	_, err = pgConn.Exec(ctx, "create temporary table foo(a int4)").ReadAll()
+	require.NoError(t, err)
+
+	_, err = pgConn.StartCopyBoth(ctx, "copy foo from stdin")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	ensureConnValid(t, pgConn)
+
+	_, err = pgConn.StartCopyBoth(ctx, "select * from missing_table")
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "42P01", pgErr.Code)
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnCopyFromQuerySyntaxError(t *testing.T) {
 	t.Parallel()
 
@@ -3148,6 +3296,59 @@ func TestPipelineCloseDetectsUnsyncedRequests(t *testing.T) {
 	require.EqualError(t, err, "pipeline has unsynced requests")
 }
 
+func TestPipelineFlushRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	pipeline := pgConn.StartPipeline(ctx)
+	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	// The result is available before Sync.
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*pgconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Equal(t, "1", string(readResult.Rows[0][0]))
+
+	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*pgconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Equal(t, "2", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*pgconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnOnPgError(t *testing.T) {
 	t.Parallel()
 
@@ -3389,3 +3590,302 @@ func TestSNISupport(t *testing.T) {
 		})
 	}
 }
+
+func TestResultReaderReadsDataRowsWithMockServer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Parse{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Describe{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Execute{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ParseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("a"), DataTypeOID: 25},
+		{Name: []byte("b"), DataTypeOID: 25},
+	}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("foo"), nil}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.NoticeResponse{Severity: "NOTICE", Message: "hello"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{{}, []byte("bar")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 2")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+	script := &pgmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	config, err := pgconn.ParseConfig(connStr)
+	require.NoError(t, err)
+	var notices []string
+	config.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) { notices = append(notices, n.Message) }
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	result := conn.ExecParams(ctx, "mocked...", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, "SELECT 2", result.CommandTag.String())
+	require.Len(t, result.FieldDescriptions, 2)
+	assert.Equal(t, [][][]byte{{[]byte("foo"), {}}, {{}, []byte("bar")}}, result.Rows)
+	assert.Equal(t, []string{"hello"}, notices)
+
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-serverErrChan)
+}
+
+func TestResultReaderFetchesPortalInPagesWithMockServer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	rowDescription := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("n"), DataTypeOID: 25}}}
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+
+	// All rows are read. The next page is requested after each PortalSuspended.
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Parse{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Describe{ObjectType: 'P'}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Execute{MaxRows: 2}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Flush{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ParseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	steps = append(steps, pgmock.SendMessage(rowDescription))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("1")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("2")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.PortalSuspended{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Execute{MaxRows: 2}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Flush{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("3")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Close{ObjectType: 'P'}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	// The reader is closed after the first page. The portal is closed without fetching the remaining rows.
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Describe{ObjectType: 'P', Name: "p"}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Execute{Portal: "p", MaxRows: 1}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Flush{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	steps = append(steps, pgmock.SendMessage(rowDescription))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("1")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.PortalSuspended{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Close{ObjectType: 'P', Name: "p"}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+	script := &pgmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := pgconn.Connect(ctx, connStr)
+	require.NoError(t, err)
+
+	result := conn.ExecParamsPortal(ctx, "", "mocked...", nil, nil, nil, nil, 2).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, "SELECT 3", result.CommandTag.String())
+	assert.Equal(t, [][][]byte{{[]byte("1")}, {[]byte("2")}, {[]byte("3")}}, result.Rows)
+
+	rr := conn.ExecPreparedPortal(ctx, "p", "ps", nil, nil, nil, 1)
+	require.True(t, rr.NextRow())
+	assert.Equal(t, [][]byte{[]byte("1")}, rr.Values())
+	commandTag, err := rr.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "", commandTag.String())
+
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-serverErrChan)
+}
+
+func TestCopyBothWithMockServer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+
+	// Session ended by the server first.
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Query{String: "START_REPLICATION"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyBothResponse{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyData{Data: []byte("from server")}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.CopyData{Data: []byte("from client")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyDone{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.CopyDone{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("START_REPLICATION")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	// Receive deadline expires while waiting for the server.
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Query{String: "START_REPLICATION"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyBothResponse{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.CopyData{Data: []byte("status")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyData{Data: []byte("after status")}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.CopyDone{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("START_REPLICATION")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	// Session aborted by the client.
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Query{String: "START_REPLICATION"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CopyBothResponse{}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.CopyFail{Message: "abort"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "57014", Message: "COPY from stdin failed: abort"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	// Statement that does not start a copy both session.
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("?column?"), DataTypeOID: 23}}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("1")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+	script := &pgmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := pgconn.Connect(ctx, connStr)
+	require.NoError(t, err)
+
+	cb, err := conn.StartCopyBoth(ctx, "START_REPLICATION")
+	require.NoError(t, err)
+	buf, err := cb.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "from server", string(buf))
+	require.NoError(t, cb.Send([]byte("from client")))
+	_, err = cb.Receive(ctx)
+	require.ErrorIs(t, err, io.EOF)
+	commandTag, err := cb.Done()
+	require.NoError(t, err)
+	assert.Equal(t, "START_REPLICATION", commandTag.String())
+
+	cb, err = conn.StartCopyBoth(ctx, "START_REPLICATION")
+	require.NoError(t, err)
+	receiveCtx, receiveCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = cb.Receive(receiveCtx)
+	receiveCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, conn.IsClosed())
+	require.NoError(t, cb.Send([]byte("status")))
+	buf, err = cb.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "after status", string(buf))
+	commandTag, err = cb.Done()
+	require.NoError(t, err)
+	assert.Equal(t, "START_REPLICATION", commandTag.String())
+
+	cb, err = conn.StartCopyBoth(ctx, "START_REPLICATION")
+	require.NoError(t, err)
+	err = cb.Fail("abort")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "57014", pgErr.Code)
+
+	_, err = conn.StartCopyBoth(ctx, "select 1")
+	require.ErrorContains(t, err, "did not start a copy both session")
+
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-serverErrChan)
+}
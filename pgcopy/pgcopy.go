@@ -0,0 +1,371 @@
+// Package pgcopy reads and writes the PostgreSQL binary COPY format.
+/*
+The binary COPY format is the format used by COPY ... (FORMAT binary). pgcopy can be used to generate files that are
+later loaded with COPY FROM and to parse the output of COPY TO without a connection to the server. Values are encoded
+and decoded with a *pgtype.Map according to the OID of each column.
+
+For more details see: https://www.postgresql.org/docs/current/sql-copy.html#id-1.9.3.55.9.4
+*/
+package pgcopy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5/internal/anynil"
+	"github.com/jackc/pgx/v5/internal/pgio"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Signature is the signature at the start of the binary COPY format.
+var Signature = []byte("PGCOPY\n\377\r\n\000")
+
+// flagHasOIDs is the header flag set when each tuple includes an OID.
+const flagHasOIDs = 1 << 16
+
+// AppendHeader appends the binary COPY header to buf.
+func AppendHeader(buf []byte) []byte {
+	buf = append(buf, Signature...)
+	buf = pgio.AppendInt32(buf, 0) // flags
+	buf = pgio.AppendInt32(buf, 0) // header extension length
+	return buf
+}
+
+// AppendTrailer appends the binary COPY trailer to buf.
+func AppendTrailer(buf []byte) []byte {
+	return pgio.AppendInt16(buf, -1)
+}
+
+// AppendValue appends the length prefixed binary encoding of value for the type oid to buf. nil values are encoded as
+// NULL. A string value that cannot be encoded directly is parsed as the text format of oid and then encoded.
+func AppendValue(m *pgtype.Map, buf []byte, oid uint32, value any) ([]byte, error) {
+	if anynil.Is(value) {
+		return pgio.AppendInt32(buf, -1), nil
+	}
+
+	sp := len(buf)
+	buf = pgio.AppendInt32(buf, -1)
+	argBuf, err := m.Encode(oid, pgtype.BinaryFormatCode, value, buf)
+	if err != nil {
+		if argBuf2, err2 := tryScanStringThenEncode(m, buf, oid, value); err2 == nil {
+			argBuf = argBuf2
+		} else {
+			return nil, err
+		}
+	}
+
+	if argBuf != nil {
+		buf = argBuf
+		pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+	}
+	return buf, nil
+}
+
+func tryScanStringThenEncode(m *pgtype.Map, buf []byte, oid uint32, value any) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+
+	var v any
+	err := m.Scan(oid, pgtype.TextFormatCode, []byte(s), &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Encode(oid, pgtype.BinaryFormatCode, v, buf)
+}
+
+// AppendRow appends the binary COPY tuple of values for the column types oids to buf.
+func AppendRow(m *pgtype.Map, buf []byte, oids []uint32, values []any) ([]byte, error) {
+	if len(values) != len(oids) {
+		return nil, fmt.Errorf("expected %d values, got %d values", len(oids), len(values))
+	}
+
+	buf = pgio.AppendInt16(buf, int16(len(oids)))
+	for i, value := range values {
+		var err error
+		buf, err = AppendValue(m, buf, oids[i], value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// Writer writes rows in the binary COPY format to an io.Writer.
+type Writer struct {
+	w    io.Writer
+	m    *pgtype.Map
+	oids []uint32
+
+	buf           []byte
+	headerWritten bool
+	err           error
+}
+
+// writerBufSize is the size at which the buffered output of a Writer is written to the underlying io.Writer.
+const writerBufSize = 65536
+
+// NewWriter returns a Writer that writes rows with the column types oids to w. Values are encoded with m.
+func NewWriter(w io.Writer, m *pgtype.Map, oids []uint32) *Writer {
+	return &Writer{w: w, m: m, oids: oids}
+}
+
+// WriteRow writes a row of values. The header is written before the first row. The output is buffered and may not be
+// written to the underlying io.Writer until Close is called.
+func (w *Writer) WriteRow(values []any) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.writeHeader()
+
+	buf, err := AppendRow(w.m, w.buf, w.oids, values)
+	if err != nil {
+		// The row is not written so the Writer can still be used.
+		return err
+	}
+	w.buf = buf
+
+	if len(w.buf) >= writerBufSize {
+		return w.flush()
+	}
+
+	return nil
+}
+
+func (w *Writer) writeHeader() {
+	if !w.headerWritten {
+		w.buf = AppendHeader(w.buf)
+		w.headerWritten = true
+	}
+}
+
+func (w *Writer) flush() error {
+	_, err := w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+	if err != nil {
+		w.err = err
+	}
+	return err
+}
+
+// Close writes the trailer and any buffered output. It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.writeHeader()
+	w.buf = AppendTrailer(w.buf)
+	err := w.flush()
+	if err == nil {
+		w.err = errors.New("writer is closed")
+	}
+	return err
+}
+
+// Reader reads rows in the binary COPY format from an io.Reader.
+type Reader struct {
+	r    *bufio.Reader
+	m    *pgtype.Map
+	oids []uint32
+
+	headerRead bool
+	values     [][]byte
+	valueLens  []int32
+	buf        []byte
+	err        error
+	done       bool
+}
+
+// NewReader returns a Reader that reads rows with the column types oids from r. Values are decoded with m.
+func NewReader(r io.Reader, m *pgtype.Map, oids []uint32) *Reader {
+	return &Reader{r: bufio.NewReader(r), m: m, oids: oids}
+}
+
+// Next reads the next row. It returns false when the trailer has been read or an error occurred. Err returns the
+// error.
+func (r *Reader) Next() bool {
+	if r.done || r.err != nil {
+		return false
+	}
+
+	if !r.headerRead {
+		r.err = r.readHeader()
+		if r.err != nil {
+			return false
+		}
+		r.headerRead = true
+	}
+
+	ok, err := r.readTuple()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	if !ok {
+		r.done = true
+		return false
+	}
+
+	return true
+}
+
+// readFull reads len(buf) bytes. An io.EOF before the trailer is read is returned as io.ErrUnexpectedEOF.
+func (r *Reader) readFull(buf []byte) error {
+	_, err := io.ReadFull(r.r, buf)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (r *Reader) readHeader() error {
+	header := make([]byte, len(Signature)+8)
+	err := r.readFull(header)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(header[:len(Signature)], Signature) {
+		return errors.New("invalid binary copy signature")
+	}
+
+	flags := binary.BigEndian.Uint32(header[len(Signature):])
+	if flags&flagHasOIDs != 0 {
+		return errors.New("binary copy data with OIDs is not supported")
+	}
+
+	extensionLen := binary.BigEndian.Uint32(header[len(Signature)+4:])
+	_, err = io.CopyN(io.Discard, r.r, int64(extensionLen))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readTuple reads the next tuple into r.values. It returns false when the trailer is read.
+func (r *Reader) readTuple() (bool, error) {
+	var fieldCountBuf [2]byte
+	err := r.readFull(fieldCountBuf[:])
+	if err != nil {
+		return false, err
+	}
+
+	fieldCount := int16(binary.BigEndian.Uint16(fieldCountBuf[:]))
+	if fieldCount == -1 {
+		return false, nil
+	}
+	if int(fieldCount) != len(r.oids) {
+		return false, fmt.Errorf("expected %d fields in copy data, got %d", len(r.oids), fieldCount)
+	}
+
+	if r.values == nil {
+		r.values = make([][]byte, fieldCount)
+		r.valueLens = make([]int32, fieldCount)
+	}
+	r.buf = r.buf[:0]
+	lens := r.valueLens
+
+	for i := range lens {
+		var lenBuf [4]byte
+		err := r.readFull(lenBuf[:])
+		if err != nil {
+			return false, err
+		}
+
+		lens[i] = int32(binary.BigEndian.Uint32(lenBuf[:]))
+		if lens[i] < 0 {
+			continue
+		}
+
+		start := len(r.buf)
+		r.buf = append(r.buf, make([]byte, lens[i])...)
+		err = r.readFull(r.buf[start:])
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// The values are sliced from r.buf after all are read as r.buf may be reallocated while reading.
+	pos := 0
+	for i, n := range lens {
+		if n < 0 {
+			r.values[i] = nil
+			continue
+		}
+		if n == 0 {
+			// r.buf may still be nil when no value has been read yet. An empty value must not be mistaken for NULL.
+			r.values[i] = []byte{}
+			continue
+		}
+		r.values[i] = r.buf[pos : pos+int(n) : pos+int(n)]
+		pos += int(n)
+	}
+
+	return true, nil
+}
+
+// RawValues returns the binary encoded values of the current row. nil is a NULL value. The returned data is only valid
+// until the next call to Next.
+func (r *Reader) RawValues() [][]byte {
+	return r.values
+}
+
+// Scan decodes the values of the current row into dest. dest can include nil to skip a value.
+func (r *Reader) Scan(dest ...any) error {
+	if len(dest) != len(r.oids) {
+		return fmt.Errorf("number of columns must equal number of destinations, got %d and %d", len(r.oids), len(dest))
+	}
+
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+
+		err := r.m.Scan(r.oids[i], pgtype.BinaryFormatCode, r.values[i], d)
+		if err != nil {
+			return fmt.Errorf("can't scan into dest[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Values returns the decoded values of the current row. Values of types not registered in the *pgtype.Map are
+// returned as []byte.
+func (r *Reader) Values() ([]any, error) {
+	values := make([]any, len(r.oids))
+
+	for i, buf := range r.values {
+		if buf == nil {
+			continue
+		}
+
+		if dt, ok := r.m.TypeForOID(r.oids[i]); ok {
+			value, err := dt.Codec.DecodeValue(r.m, r.oids[i], pgtype.BinaryFormatCode, buf)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		} else {
+			newBuf := make([]byte, len(buf))
+			copy(newBuf, buf)
+			values[i] = newBuf
+		}
+	}
+
+	return values, nil
+}
+
+// Err returns the error that stopped Next. A stream that ends before the trailer results in io.ErrUnexpectedEOF.
+func (r *Reader) Err() error {
+	return r.err
+}
@@ -0,0 +1,143 @@
+package pgcopy_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgcopy"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterReaderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	oids := []uint32{pgtype.Int4OID, pgtype.TextOID, pgtype.TimestamptzOID, pgtype.NumericOID}
+
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := [][]any{
+		{int32(1), "foo", ts, "1.5"},
+		{nil, nil, nil, nil},
+		{int32(3), "", ts.Add(time.Hour), 42},
+	}
+
+	var buf bytes.Buffer
+	w := pgcopy.NewWriter(&buf, m, oids)
+	for _, row := range input {
+		require.NoError(t, w.WriteRow(row))
+	}
+	require.Error(t, w.WriteRow([]any{int32(1)}))
+	require.NoError(t, w.Close())
+	require.True(t, bytes.HasPrefix(buf.Bytes(), pgcopy.Signature))
+	require.True(t, bytes.HasSuffix(buf.Bytes(), []byte{0xff, 0xff}))
+
+	r := pgcopy.NewReader(&buf, m, oids)
+
+	require.True(t, r.Next())
+	var n int32
+	var s string
+	var tsOut time.Time
+	var num pgtype.Numeric
+	require.NoError(t, r.Scan(&n, &s, &tsOut, &num))
+	assert.EqualValues(t, 1, n)
+	assert.Equal(t, "foo", s)
+	assert.True(t, ts.Equal(tsOut))
+	f, err := num.Float64Value()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, f.Float64)
+
+	require.True(t, r.Next())
+	assert.Equal(t, [][]byte{nil, nil, nil, nil}, r.RawValues())
+	values, err := r.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, nil, nil, nil}, values)
+
+	require.True(t, r.Next())
+	var ns *string
+	require.NoError(t, r.Scan(nil, &ns, nil, nil))
+	require.NotNil(t, ns)
+	assert.Equal(t, "", *ns)
+
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestReaderEmptyValueInFirstRow(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	oids := []uint32{pgtype.TextOID, pgtype.ByteaOID}
+
+	var buf bytes.Buffer
+	w := pgcopy.NewWriter(&buf, m, oids)
+	require.NoError(t, w.WriteRow([]any{"", []byte{}}))
+	require.NoError(t, w.Close())
+
+	r := pgcopy.NewReader(&buf, m, oids)
+	require.True(t, r.Next())
+	assert.Equal(t, [][]byte{{}, {}}, r.RawValues())
+	require.NotNil(t, r.RawValues()[0])
+	require.NotNil(t, r.RawValues()[1])
+
+	var s string
+	var b []byte
+	require.NoError(t, r.Scan(&s, &b))
+	assert.Equal(t, "", s)
+	assert.Equal(t, []byte{}, b)
+
+	values, err := r.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"", []byte{}}, values)
+
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestWriterEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := pgcopy.NewWriter(&buf, pgtype.NewMap(), []uint32{pgtype.Int4OID})
+	require.NoError(t, w.Close())
+
+	expected := pgcopy.AppendTrailer(pgcopy.AppendHeader(nil))
+	assert.Equal(t, expected, buf.Bytes())
+
+	r := pgcopy.NewReader(&buf, pgtype.NewMap(), []uint32{pgtype.Int4OID})
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestReaderErrors(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	oids := []uint32{pgtype.Int4OID}
+
+	r := pgcopy.NewReader(bytes.NewReader([]byte("not a copy file at all")), m, oids)
+	require.False(t, r.Next())
+	require.Error(t, r.Err())
+
+	buf, err := pgcopy.AppendRow(m, pgcopy.AppendHeader(nil), oids, []any{int32(1)})
+	require.NoError(t, err)
+
+	// Missing trailer.
+	r = pgcopy.NewReader(bytes.NewReader(buf), m, oids)
+	require.True(t, r.Next())
+	require.False(t, r.Next())
+	require.ErrorIs(t, r.Err(), io.ErrUnexpectedEOF)
+
+	// Truncated row.
+	r = pgcopy.NewReader(bytes.NewReader(buf[:len(buf)-2]), m, oids)
+	require.False(t, r.Next())
+	require.ErrorIs(t, r.Err(), io.ErrUnexpectedEOF)
+
+	// Wrong number of columns.
+	r = pgcopy.NewReader(bytes.NewReader(buf), m, []uint32{pgtype.Int4OID, pgtype.Int4OID})
+	require.False(t, r.Next())
+	require.ErrorContains(t, r.Err(), "expected 2 fields")
+}
@@ -140,3 +140,74 @@ func (dst *DataRow) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+// DataRowReader iterates over the column values of an encoded DataRow message body without allocating. The values it
+// returns reference the message body so they are only valid as long as the body is. It is typically used with
+// Frontend.ReceiveRaw.
+//
+// The zero value is ready to use after calling Reset.
+type DataRowReader struct {
+	src       []byte
+	rp        int
+	count     int
+	remaining int
+	value     []byte
+	err       error
+}
+
+// Reset prepares r to read the DataRow message body src. It returns an error if src does not start with a valid column
+// count.
+func (r *DataRowReader) Reset(src []byte) error {
+	*r = DataRowReader{src: src}
+	if len(src) < 2 {
+		r.err = &invalidMessageFormatErr{messageType: "DataRow"}
+		return r.err
+	}
+	r.count = int(binary.BigEndian.Uint16(src))
+	r.remaining = r.count
+	r.rp = 2
+	return nil
+}
+
+// Len returns the number of columns in the row.
+func (r *DataRowReader) Len() int {
+	return r.count
+}
+
+// Next advances to the next column value. It returns false when there are no more columns or an error occurred.
+func (r *DataRowReader) Next() bool {
+	if r.err != nil || r.remaining == 0 {
+		return false
+	}
+
+	if len(r.src[r.rp:]) < 4 {
+		r.err = &invalidMessageFormatErr{messageType: "DataRow"}
+		return false
+	}
+	valueLen := int(int32(binary.BigEndian.Uint32(r.src[r.rp:])))
+	r.rp += 4
+
+	if valueLen == -1 {
+		r.value = nil
+	} else {
+		if len(r.src[r.rp:]) < valueLen || valueLen < 0 {
+			r.err = &invalidMessageFormatErr{messageType: "DataRow"}
+			return false
+		}
+		r.value = r.src[r.rp : r.rp+valueLen : r.rp+valueLen]
+		r.rp += valueLen
+	}
+
+	r.remaining--
+	return true
+}
+
+// Value returns the current column value. It is nil for a NULL value.
+func (r *DataRowReader) Value() []byte {
+	return r.value
+}
+
+// Err returns any error encountered while reading the row.
+func (r *DataRowReader) Err() error {
+	return r.err
+}
@@ -216,17 +216,58 @@ func translateEOFtoErrUnexpectedEOF(err error) error {
 
 // Receive receives a message from the backend. The returned message is only valid until the next call to Receive.
 func (f *Frontend) Receive() (BackendMessage, error) {
+	msgType, msgBody, err := f.receiveRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := f.DecodeMessage(msgType, msgBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.tracer != nil {
+		f.tracer.traceMessage('B', int32(5+len(msgBody)), msg)
+	}
+
+	return msg, nil
+}
+
+// ReceiveRaw receives a message from the backend without decoding it. It returns the message type identifier and the
+// message body (the message without the 1 byte message type identifier and 4 byte message length). msgBody is only
+// valid until the next call to Receive or ReceiveRaw.
+//
+// ReceiveRaw does not allocate. It is intended for high throughput readers that handle common messages such as DataRow
+// directly (see DataRowReader) and use DecodeMessage for the rest.
+func (f *Frontend) ReceiveRaw() (msgType byte, msgBody []byte, err error) {
+	msgType, msgBody, err = f.receiveRaw()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if f.tracer != nil {
+		msg, err := f.DecodeMessage(msgType, msgBody)
+		if err != nil {
+			return 0, nil, err
+		}
+		f.tracer.traceMessage('B', int32(5+len(msgBody)), msg)
+	}
+
+	return msgType, msgBody, nil
+}
+
+func (f *Frontend) receiveRaw() (byte, []byte, error) {
 	if !f.partialMsg {
 		header, err := f.cr.Next(5)
 		if err != nil {
-			return nil, translateEOFtoErrUnexpectedEOF(err)
+			return 0, nil, translateEOFtoErrUnexpectedEOF(err)
 		}
 
 		f.msgType = header[0]
 
 		msgLength := int(binary.BigEndian.Uint32(header[1:]))
 		if msgLength < 4 {
-			return nil, fmt.Errorf("invalid message length: %d", msgLength)
+			return 0, nil, fmt.Errorf("invalid message length: %d", msgLength)
 		}
 
 		f.bodyLen = msgLength - 4
@@ -235,13 +276,19 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 
 	msgBody, err := f.cr.Next(f.bodyLen)
 	if err != nil {
-		return nil, translateEOFtoErrUnexpectedEOF(err)
+		return 0, nil, translateEOFtoErrUnexpectedEOF(err)
 	}
 
 	f.partialMsg = false
 
+	return f.msgType, msgBody, nil
+}
+
+// DecodeMessage decodes a message body received with ReceiveRaw. The returned message is only valid until the next
+// call to Receive or DecodeMessage.
+func (f *Frontend) DecodeMessage(msgType byte, msgBody []byte) (BackendMessage, error) {
 	var msg BackendMessage
-	switch f.msgType {
+	switch msgType {
 	case '1':
 		msg = &f.parseComplete
 	case '2':
@@ -293,18 +340,14 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 	case 'Z':
 		msg = &f.readyForQuery
 	default:
-		return nil, fmt.Errorf("unknown message type: %c", f.msgType)
+		return nil, fmt.Errorf("unknown message type: %c", msgType)
 	}
 
-	err = msg.Decode(msgBody)
+	err := msg.Decode(msgBody)
 	if err != nil {
 		return nil, err
 	}
 
-	if f.tracer != nil {
-		f.tracer.traceMessage('B', int32(5+len(msgBody)), msg)
-	}
-
 	return msg, nil
 }
 
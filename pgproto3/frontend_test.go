@@ -1,6 +1,7 @@
 package pgproto3_test
 
 import (
+	"bytes"
 	"io"
 	"testing"
 
@@ -115,3 +116,74 @@ func TestErrorResponse(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
+
+func TestFrontendReceiveRaw(t *testing.T) {
+	t.Parallel()
+
+	var buf []byte
+	buf = (&pgproto3.DataRow{Values: [][]byte{[]byte("foo"), nil, {}}}).Encode(buf)
+	buf = (&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}).Encode(buf)
+
+	frontend := pgproto3.NewFrontend(bytes.NewReader(buf), nil)
+
+	msgType, msgBody, err := frontend.ReceiveRaw()
+	require.NoError(t, err)
+	require.Equal(t, byte('D'), msgType)
+
+	var dr pgproto3.DataRowReader
+	require.NoError(t, dr.Reset(msgBody))
+	require.Equal(t, 3, dr.Len())
+	var values [][]byte
+	for dr.Next() {
+		values = append(values, dr.Value())
+	}
+	require.NoError(t, dr.Err())
+	assert.Equal(t, [][]byte{[]byte("foo"), nil, {}}, values)
+
+	msgType, msgBody, err = frontend.ReceiveRaw()
+	require.NoError(t, err)
+	require.Equal(t, byte('C'), msgType)
+
+	msg, err := frontend.DecodeMessage(msgType, msgBody)
+	require.NoError(t, err)
+	assert.Equal(t, &pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}, msg)
+
+	_, _, err = frontend.ReceiveRaw()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFrontendReceiveRawDataRowDoesNotAllocate(t *testing.T) {
+	var row []byte
+	row = (&pgproto3.DataRow{Values: [][]byte{[]byte("foo"), nil, []byte("bar")}}).Encode(row)
+
+	r := &bytes.Reader{}
+	frontend := pgproto3.NewFrontend(r, nil)
+	var dr pgproto3.DataRowReader
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(row)
+		_, msgBody, err := frontend.ReceiveRaw()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dr.Reset(msgBody)
+		for dr.Next() {
+		}
+		if dr.Err() != nil {
+			t.Fatal(dr.Err())
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestDataRowReaderInvalid(t *testing.T) {
+	t.Parallel()
+
+	var dr pgproto3.DataRowReader
+	require.Error(t, dr.Reset([]byte{0}))
+
+	// One column claiming 10 bytes but only 2 present.
+	require.NoError(t, dr.Reset([]byte{0, 1, 0, 0, 0, 10, 'a', 'b'}))
+	assert.False(t, dr.Next())
+	assert.Error(t, dr.Err())
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -91,6 +92,8 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceFunctionCallResponse(sender, encodedLen, msg)
 	case *GSSEncRequest:
 		t.traceGSSEncRequest(sender, encodedLen, msg)
+	case *GSSResponse:
+		t.traceGSSResponse(sender, encodedLen, msg)
 	case *NoData:
 		t.traceNoData(sender, encodedLen, msg)
 	case *NoticeResponse:
@@ -105,6 +108,8 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceParse(sender, encodedLen, msg)
 	case *ParseComplete:
 		t.traceParseComplete(sender, encodedLen, msg)
+	case *PasswordMessage:
+		t.tracePasswordMessage(sender, encodedLen, msg)
 	case *PortalSuspended:
 		t.tracePortalSuspended(sender, encodedLen, msg)
 	case *Query:
@@ -113,6 +118,10 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceReadyForQuery(sender, encodedLen, msg)
 	case *RowDescription:
 		t.traceRowDescription(sender, encodedLen, msg)
+	case *SASLInitialResponse:
+		t.traceSASLInitialResponse(sender, encodedLen, msg)
+	case *SASLResponse:
+		t.traceSASLResponse(sender, encodedLen, msg)
 	case *SSLRequest:
 		t.traceSSLRequest(sender, encodedLen, msg)
 	case *StartupMessage:
@@ -176,7 +185,11 @@ func (t *tracer) traceBind(sender byte, encodedLen int32, msg *Bind) {
 		}
 		fmt.Fprintf(t.buf, " %d", len(msg.Parameters))
 		for _, p := range msg.Parameters {
-			fmt.Fprintf(t.buf, " %s", traceSingleQuotedString(p))
+			if p == nil {
+				t.buf.WriteString(" -1")
+			} else {
+				fmt.Fprintf(t.buf, " %d %s", len(p), traceSingleQuotedString(p))
+			}
 		}
 		fmt.Fprintf(t.buf, " %d", len(msg.ResultFormatCodes))
 		for _, fc := range msg.ResultFormatCodes {
@@ -190,11 +203,20 @@ func (t *tracer) traceBindComplete(sender byte, encodedLen int32, msg *BindCompl
 }
 
 func (t *tracer) traceCancelRequest(sender byte, encodedLen int32, msg *CancelRequest) {
-	t.writeTrace(sender, encodedLen, "CancelRequest", nil)
+	t.writeTrace(sender, encodedLen, "CancelRequest", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", cancelRequestCode>>16, cancelRequestCode&0xffff)
+		if t.RegressMode {
+			t.buf.WriteString(" NNNN NNNN")
+		} else {
+			fmt.Fprintf(t.buf, " %d %d", msg.ProcessID, msg.SecretKey)
+		}
+	})
 }
 
 func (t *tracer) traceClose(sender byte, encodedLen int32, msg *Close) {
-	t.writeTrace(sender, encodedLen, "Close", nil)
+	t.writeTrace(sender, encodedLen, "Close", func() {
+		fmt.Fprintf(t.buf, "\t %s %s", traceByte1(msg.ObjectType), traceDoubleQuotedString([]byte(msg.Name)))
+	})
 }
 
 func (t *tracer) traceCloseComplete(sender byte, encodedLen int32, msg *CloseComplete) {
@@ -208,7 +230,9 @@ func (t *tracer) traceCommandComplete(sender byte, encodedLen int32, msg *Comman
 }
 
 func (t *tracer) traceCopyBothResponse(sender byte, encodedLen int32, msg *CopyBothResponse) {
-	t.writeTrace(sender, encodedLen, "CopyBothResponse", nil)
+	t.writeTrace(sender, encodedLen, "CopyBothResponse", func() {
+		t.writeCopyResponseDetails(msg.OverallFormat, msg.ColumnFormatCodes)
+	})
 }
 
 func (t *tracer) traceCopyData(sender byte, encodedLen int32, msg *CopyData) {
@@ -226,11 +250,22 @@ func (t *tracer) traceCopyFail(sender byte, encodedLen int32, msg *CopyFail) {
 }
 
 func (t *tracer) traceCopyInResponse(sender byte, encodedLen int32, msg *CopyInResponse) {
-	t.writeTrace(sender, encodedLen, "CopyInResponse", nil)
+	t.writeTrace(sender, encodedLen, "CopyInResponse", func() {
+		t.writeCopyResponseDetails(msg.OverallFormat, msg.ColumnFormatCodes)
+	})
 }
 
 func (t *tracer) traceCopyOutResponse(sender byte, encodedLen int32, msg *CopyOutResponse) {
-	t.writeTrace(sender, encodedLen, "CopyOutResponse", nil)
+	t.writeTrace(sender, encodedLen, "CopyOutResponse", func() {
+		t.writeCopyResponseDetails(msg.OverallFormat, msg.ColumnFormatCodes)
+	})
+}
+
+func (t *tracer) writeCopyResponseDetails(overallFormat byte, columnFormatCodes []uint16) {
+	fmt.Fprintf(t.buf, "\t %s %d", traceByte1(overallFormat), len(columnFormatCodes))
+	for _, fc := range columnFormatCodes {
+		fmt.Fprintf(t.buf, " %d", fc)
+	}
 }
 
 func (t *tracer) traceDataRow(sender byte, encodedLen int32, msg *DataRow) {
@@ -257,7 +292,38 @@ func (t *tracer) traceEmptyQueryResponse(sender byte, encodedLen int32, msg *Emp
 }
 
 func (t *tracer) traceErrorResponse(sender byte, encodedLen int32, msg *ErrorResponse) {
-	t.writeTrace(sender, encodedLen, "ErrorResponse", nil)
+	t.writeTrace(sender, encodedLen, "ErrorResponse", func() {
+		t.writeErrorNoticeDetails(msg.marshalBinary('E'))
+	})
+}
+
+// writeErrorNoticeDetails writes the fields of the encoded ErrorResponse or NoticeResponse message buf. It is roughly
+// equivalent to pqTraceOutputErrorNotice in libpq.
+func (t *tracer) writeErrorNoticeDetails(buf []byte) {
+	t.buf.WriteByte('\t')
+	buf = buf[5:]
+	for len(buf) > 0 {
+		field := buf[0]
+		fmt.Fprintf(t.buf, " %s", traceByte1(field))
+		buf = buf[1:]
+		if field == 0 {
+			break
+		}
+
+		idx := bytes.IndexByte(buf, 0)
+		if idx < 0 {
+			idx = len(buf)
+		}
+		if t.RegressMode && (field == 'L' || field == 'F' || field == 'R') {
+			t.buf.WriteString(` "SSSS"`)
+		} else {
+			fmt.Fprintf(t.buf, " %s", traceDoubleQuotedString(buf[:idx]))
+		}
+		if idx < len(buf) {
+			idx++
+		}
+		buf = buf[idx:]
+	}
 }
 
 func (t *tracer) TraceQueryute(sender byte, encodedLen int32, msg *Execute) {
@@ -271,15 +337,41 @@ func (t *tracer) traceFlush(sender byte, encodedLen int32, msg *Flush) {
 }
 
 func (t *tracer) traceFunctionCall(sender byte, encodedLen int32, msg *FunctionCall) {
-	t.writeTrace(sender, encodedLen, "FunctionCall", nil)
+	t.writeTrace(sender, encodedLen, "FunctionCall", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", msg.Function, len(msg.ArgFormatCodes))
+		for _, fc := range msg.ArgFormatCodes {
+			fmt.Fprintf(t.buf, " %d", fc)
+		}
+		fmt.Fprintf(t.buf, " %d", len(msg.Arguments))
+		for _, arg := range msg.Arguments {
+			if arg == nil {
+				t.buf.WriteString(" -1")
+			} else {
+				fmt.Fprintf(t.buf, " %d %s", len(arg), traceSingleQuotedString(arg))
+			}
+		}
+		fmt.Fprintf(t.buf, " %d", msg.ResultFormatCode)
+	})
 }
 
 func (t *tracer) traceFunctionCallResponse(sender byte, encodedLen int32, msg *FunctionCallResponse) {
-	t.writeTrace(sender, encodedLen, "FunctionCallResponse", nil)
+	t.writeTrace(sender, encodedLen, "FunctionCallResponse", func() {
+		if msg.Result == nil {
+			t.buf.WriteString("\t -1")
+		} else {
+			fmt.Fprintf(t.buf, "\t %d %s", len(msg.Result), traceSingleQuotedString(msg.Result))
+		}
+	})
 }
 
 func (t *tracer) traceGSSEncRequest(sender byte, encodedLen int32, msg *GSSEncRequest) {
-	t.writeTrace(sender, encodedLen, "GSSEncRequest", nil)
+	t.writeTrace(sender, encodedLen, "GSSEncRequest", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", gssEncReqNumber>>16, gssEncReqNumber&0xffff)
+	})
+}
+
+func (t *tracer) traceGSSResponse(sender byte, encodedLen int32, msg *GSSResponse) {
+	t.writeTrace(sender, encodedLen, "GSSResponse", nil)
 }
 
 func (t *tracer) traceNoData(sender byte, encodedLen int32, msg *NoData) {
@@ -287,7 +379,9 @@ func (t *tracer) traceNoData(sender byte, encodedLen int32, msg *NoData) {
 }
 
 func (t *tracer) traceNoticeResponse(sender byte, encodedLen int32, msg *NoticeResponse) {
-	t.writeTrace(sender, encodedLen, "NoticeResponse", nil)
+	t.writeTrace(sender, encodedLen, "NoticeResponse", func() {
+		t.writeErrorNoticeDetails((*ErrorResponse)(msg).marshalBinary('N'))
+	})
 }
 
 func (t *tracer) traceNotificationResponse(sender byte, encodedLen int32, msg *NotificationResponse) {
@@ -297,7 +391,12 @@ func (t *tracer) traceNotificationResponse(sender byte, encodedLen int32, msg *N
 }
 
 func (t *tracer) traceParameterDescription(sender byte, encodedLen int32, msg *ParameterDescription) {
-	t.writeTrace(sender, encodedLen, "ParameterDescription", nil)
+	t.writeTrace(sender, encodedLen, "ParameterDescription", func() {
+		fmt.Fprintf(t.buf, "\t %d", len(msg.ParameterOIDs))
+		for _, oid := range msg.ParameterOIDs {
+			fmt.Fprintf(t.buf, " %d", oid)
+		}
+	})
 }
 
 func (t *tracer) traceParameterStatus(sender byte, encodedLen int32, msg *ParameterStatus) {
@@ -319,6 +418,10 @@ func (t *tracer) traceParseComplete(sender byte, encodedLen int32, msg *ParseCom
 	t.writeTrace(sender, encodedLen, "ParseComplete", nil)
 }
 
+func (t *tracer) tracePasswordMessage(sender byte, encodedLen int32, msg *PasswordMessage) {
+	t.writeTrace(sender, encodedLen, "PasswordMessage", nil)
+}
+
 func (t *tracer) tracePortalSuspended(sender byte, encodedLen int32, msg *PortalSuspended) {
 	t.writeTrace(sender, encodedLen, "PortalSuspended", nil)
 }
@@ -344,12 +447,35 @@ func (t *tracer) traceRowDescription(sender byte, encodedLen int32, msg *RowDesc
 	})
 }
 
+func (t *tracer) traceSASLInitialResponse(sender byte, encodedLen int32, msg *SASLInitialResponse) {
+	t.writeTrace(sender, encodedLen, "SASLInitialResponse", func() {
+		fmt.Fprintf(t.buf, "\t %s", traceDoubleQuotedString([]byte(msg.AuthMechanism)))
+	})
+}
+
+func (t *tracer) traceSASLResponse(sender byte, encodedLen int32, msg *SASLResponse) {
+	t.writeTrace(sender, encodedLen, "SASLResponse", nil)
+}
+
 func (t *tracer) traceSSLRequest(sender byte, encodedLen int32, msg *SSLRequest) {
-	t.writeTrace(sender, encodedLen, "SSLRequest", nil)
+	t.writeTrace(sender, encodedLen, "SSLRequest", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", sslRequestNumber>>16, sslRequestNumber&0xffff)
+	})
 }
 
 func (t *tracer) traceStartupMessage(sender byte, encodedLen int32, msg *StartupMessage) {
-	t.writeTrace(sender, encodedLen, "StartupMessage", nil)
+	t.writeTrace(sender, encodedLen, "StartupMessage", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", msg.ProtocolVersion>>16, msg.ProtocolVersion&0xffff)
+
+		keys := make([]string, 0, len(msg.Parameters))
+		for k := range msg.Parameters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(t.buf, " %s %s", traceDoubleQuotedString([]byte(k)), traceDoubleQuotedString([]byte(msg.Parameters[k])))
+		}
+	})
 }
 
 func (t *tracer) traceSync(sender byte, encodedLen int32, msg *Sync) {
@@ -397,6 +523,15 @@ func traceDoubleQuotedString(buf []byte) string {
 	return `"` + string(buf) + `"`
 }
 
+// traceByte1 returns b as a single character or hex-escaped if it is non-printable. It is roughly equivalent to
+// pqTraceOutputByte1 in libpq.
+func traceByte1(b byte) string {
+	if b < 32 || b > 126 {
+		return fmt.Sprintf(`\x%02x`, b)
+	}
+	return string(b)
+}
+
 // traceSingleQuotedString returns buf as a single-quoted string with non-printable characters hex-escaped. It is
 // roughly equivalent to pqTraceOutputNchar in libpq.
 func traceSingleQuotedString(buf []byte) string {
@@ -405,7 +540,7 @@ func traceSingleQuotedString(buf []byte) string {
 	sb.WriteByte('\'')
 	for _, b := range buf {
 		if b < 32 || b > 126 {
-			fmt.Fprintf(sb, `\x%x`, b)
+			fmt.Fprintf(sb, `\x%02x`, b)
 		} else {
 			sb.WriteByte(b)
 		}
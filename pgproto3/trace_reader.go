@@ -0,0 +1,761 @@
+package pgproto3
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TraceRecord is a single message read from trace output.
+type TraceRecord struct {
+	// Time is when the message was traced. It is the zero time if the trace was written without timestamps.
+	Time time.Time
+
+	// Sender is 'F' for a message sent by the frontend and 'B' for a message sent by the backend.
+	Sender byte
+
+	// EncodedLen is the length of the encoded message as reported by the trace. It includes the 1 byte message type
+	// identifier for messages that have one.
+	EncodedLen int32
+
+	// Message is the decoded message. It is a FrontendMessage when Sender is 'F' and a BackendMessage when Sender is
+	// 'B'. Fields that are not present in the trace (e.g. the data of a CopyData message) are left empty.
+	Message Message
+}
+
+// TraceReader reads messages from trace output. It understands the format written by Frontend.Trace and
+// Backend.Trace as well as the format written by the libpq function PQtrace.
+//
+// The trace format does not escape double-quoted strings and may contain newlines inside of strings. TraceReader
+// uses the structure of each message type to find field boundaries, but a string that contains a double quote followed
+// by a space may be split incorrectly.
+type TraceReader struct {
+	r      *bufio.Reader
+	lineNo int
+
+	pendingLine   string
+	pendingLineNo int
+	hasPending    bool
+}
+
+// NewTraceReader creates a new TraceReader that reads trace output from r.
+func NewTraceReader(r io.Reader) *TraceReader {
+	return &TraceReader{r: bufio.NewReader(r)}
+}
+
+// ReadTrace reads all messages from the trace output in r.
+func ReadTrace(r io.Reader) ([]*TraceRecord, error) {
+	tr := NewTraceReader(r)
+	var records []*TraceRecord
+	for {
+		record, err := tr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// traceLineRegexp matches the first line of a traced message. pgx writes the message type before the length and
+// libpq writes the length before the message type.
+var traceLineRegexp = regexp.MustCompile(`^(?:(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d+)\t)?([FB])\t(?:([A-Za-z]+)\t(-?\d+)|(-?\d+)\t([A-Za-z]+))(\t.*)?$`)
+
+// Read reads the next message. It returns io.EOF when there are no more messages.
+func (tr *TraceReader) Read() (*TraceRecord, error) {
+	line, lineNo, err := tr.nextLine()
+	if err != nil {
+		return nil, err
+	}
+	for line == "" {
+		line, lineNo, err = tr.nextLine()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := traceLineRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("trace line %d: invalid format", lineNo)
+	}
+
+	// Strings can contain newlines. Any following lines that do not start a new message belong to this one.
+	details := m[7]
+	for {
+		next, nextLineNo, err := tr.nextLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if traceLineRegexp.MatchString(next) {
+			tr.unreadLine(next, nextLineNo)
+			break
+		}
+		details += "\n" + next
+	}
+	details = strings.TrimPrefix(details, "\t")
+	details = strings.TrimRight(details, "\n")
+
+	record := &TraceRecord{Sender: m[2][0]}
+
+	if m[1] != "" {
+		record.Time, err = time.ParseInLocation("2006-01-02 15:04:05.999999999", m[1], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: invalid timestamp: %w", lineNo, err)
+		}
+	}
+
+	var msgType, lenStr string
+	libpqFormat := m[3] == ""
+	if libpqFormat {
+		lenStr, msgType = m[5], m[6]
+	} else {
+		msgType, lenStr = m[3], m[4]
+	}
+
+	n, err := strconv.ParseInt(lenStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("trace line %d: invalid length: %w", lineNo, err)
+	}
+	record.EncodedLen = int32(n)
+
+	p := &traceDetailsParser{src: details}
+	record.Message, err = p.parseMessage(record.Sender, msgType)
+	if err != nil {
+		return nil, fmt.Errorf("trace line %d: %s: %w", lineNo, msgType, err)
+	}
+
+	// libpq reports the value of the message length field which does not include the message type identifier.
+	if libpqFormat {
+		switch record.Message.(type) {
+		case *StartupMessage, *SSLRequest, *GSSEncRequest, *CancelRequest:
+		default:
+			record.EncodedLen++
+		}
+	}
+
+	return record, nil
+}
+
+func (tr *TraceReader) nextLine() (string, int, error) {
+	if tr.hasPending {
+		tr.hasPending = false
+		return tr.pendingLine, tr.pendingLineNo, nil
+	}
+
+	line, err := tr.r.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", 0, err
+	}
+	tr.lineNo++
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, tr.lineNo, nil
+}
+
+func (tr *TraceReader) unreadLine(line string, lineNo int) {
+	tr.pendingLine = line
+	tr.pendingLineNo = lineNo
+	tr.hasPending = true
+}
+
+// traceDetailsParser parses the space separated fields that follow the message type and length.
+type traceDetailsParser struct {
+	src string
+	rp  int
+	err error
+}
+
+var errTraceDetailsMissing = errors.New("missing details")
+
+func (p *traceDetailsParser) parseMessage(sender byte, msgType string) (Message, error) {
+	switch sender {
+	case 'F':
+		return p.parseFrontendMessage(msgType)
+	default:
+		return p.parseBackendMessage(msgType)
+	}
+}
+
+func (p *traceDetailsParser) parseFrontendMessage(msgType string) (FrontendMessage, error) {
+	switch msgType {
+	case "Bind":
+		return p.parseBind()
+	case "CancelRequest":
+		msg := &CancelRequest{}
+		if p.done() {
+			return msg, nil
+		}
+		p.int(16)
+		p.int(16)
+		if p.peekSuppressed() {
+			p.suppressed()
+			p.suppressed()
+			return msg, p.finish()
+		}
+		msg.ProcessID = uint32(p.int(32))
+		msg.SecretKey = uint32(p.int(32))
+		return msg, p.finish()
+	case "Close":
+		msg := &Close{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.ObjectType = p.byte1()
+		msg.Name = p.str()
+		return msg, p.finish()
+	case "CopyData":
+		return &CopyData{}, nil
+	case "CopyDone":
+		return &CopyDone{}, nil
+	case "CopyFail":
+		msg := &CopyFail{}
+		msg.Message = p.strToEnd()
+		return msg, p.finish()
+	case "Describe":
+		msg := &Describe{}
+		msg.ObjectType = p.byte1()
+		msg.Name = p.str()
+		return msg, p.finish()
+	case "Execute":
+		msg := &Execute{}
+		msg.Portal = p.str()
+		msg.MaxRows = uint32(p.int(32))
+		return msg, p.finish()
+	case "Flush":
+		return &Flush{}, nil
+	case "FunctionCall":
+		msg := &FunctionCall{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.Function = uint32(p.int(32))
+		msg.ArgFormatCodes = p.uint16List()
+		msg.Arguments = p.lengthPrefixedValues()
+		msg.ResultFormatCode = uint16(p.int(16))
+		return msg, p.finish()
+	case "GSSEncRequest", "GSSENCRequest":
+		return &GSSEncRequest{}, nil
+	case "GSSResponse":
+		return &GSSResponse{}, nil
+	case "Parse":
+		return p.parseParse()
+	case "PasswordMessage":
+		return &PasswordMessage{}, nil
+	case "Query":
+		msg := &Query{}
+		msg.String = p.strToEnd()
+		return msg, p.finish()
+	case "SASLInitialResponse":
+		msg := &SASLInitialResponse{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.AuthMechanism = p.str()
+		return msg, p.finish()
+	case "SASLResponse":
+		return &SASLResponse{}, nil
+	case "SSLRequest":
+		return &SSLRequest{}, nil
+	case "StartupMessage":
+		msg := &StartupMessage{ProtocolVersion: ProtocolVersionNumber}
+		if p.done() {
+			return msg, nil
+		}
+		major := p.int(16)
+		minor := p.int(16)
+		msg.ProtocolVersion = uint32(major)<<16 | uint32(minor)
+		msg.Parameters = make(map[string]string)
+		for !p.done() && p.err == nil {
+			k := p.str()
+			msg.Parameters[k] = p.str()
+		}
+		return msg, p.finish()
+	case "Sync":
+		return &Sync{}, nil
+	case "Terminate":
+		return &Terminate{}, nil
+	default:
+		return nil, errors.New("unknown frontend message type")
+	}
+}
+
+func (p *traceDetailsParser) parseBackendMessage(msgType string) (BackendMessage, error) {
+	switch msgType {
+	case "Authentication":
+		// libpq writes only the authentication type.
+		switch authType := p.int(32); authType {
+		case AuthTypeOk:
+			return &AuthenticationOk{}, p.finish()
+		case AuthTypeCleartextPassword:
+			return &AuthenticationCleartextPassword{}, p.finish()
+		case AuthTypeMD5Password:
+			return &AuthenticationMD5Password{}, p.finish()
+		case AuthTypeGSS:
+			return &AuthenticationGSS{}, p.finish()
+		case AuthTypeGSSCont:
+			return &AuthenticationGSSContinue{}, p.finish()
+		case AuthTypeSASL:
+			return &AuthenticationSASL{}, p.finish()
+		case AuthTypeSASLContinue:
+			return &AuthenticationSASLContinue{}, p.finish()
+		case AuthTypeSASLFinal:
+			return &AuthenticationSASLFinal{}, p.finish()
+		default:
+			if p.err != nil {
+				return nil, p.err
+			}
+			return nil, fmt.Errorf("unknown authentication type: %d", authType)
+		}
+	case "AuthenticationCleartextPassword":
+		return &AuthenticationCleartextPassword{}, nil
+	case "AuthenticationGSS":
+		return &AuthenticationGSS{}, nil
+	case "AuthenticationGSSContinue":
+		return &AuthenticationGSSContinue{}, nil
+	case "AuthenticationMD5Password":
+		return &AuthenticationMD5Password{}, nil
+	case "AuthenticationOk":
+		return &AuthenticationOk{}, nil
+	case "AuthenticationSASL":
+		return &AuthenticationSASL{}, nil
+	case "AuthenticationSASLContinue":
+		return &AuthenticationSASLContinue{}, nil
+	case "AuthenticationSASLFinal":
+		return &AuthenticationSASLFinal{}, nil
+	case "BackendKeyData":
+		msg := &BackendKeyData{}
+		if p.peekSuppressed() {
+			p.suppressed()
+			p.suppressed()
+			return msg, p.finish()
+		}
+		msg.ProcessID = uint32(p.int(32))
+		msg.SecretKey = uint32(p.int(32))
+		return msg, p.finish()
+	case "BindComplete":
+		return &BindComplete{}, nil
+	case "CloseComplete":
+		return &CloseComplete{}, nil
+	case "CommandComplete":
+		msg := &CommandComplete{}
+		msg.CommandTag = []byte(p.str())
+		return msg, p.finish()
+	case "CopyBothResponse":
+		msg := &CopyBothResponse{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.OverallFormat = p.int8()
+		msg.ColumnFormatCodes = p.uint16List()
+		return msg, p.finish()
+	case "CopyData":
+		return &CopyData{}, nil
+	case "CopyDone":
+		return &CopyDone{}, nil
+	case "CopyInResponse":
+		msg := &CopyInResponse{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.OverallFormat = p.int8()
+		msg.ColumnFormatCodes = p.uint16List()
+		return msg, p.finish()
+	case "CopyOutResponse":
+		msg := &CopyOutResponse{}
+		if p.done() {
+			return msg, nil
+		}
+		msg.OverallFormat = p.int8()
+		msg.ColumnFormatCodes = p.uint16List()
+		return msg, p.finish()
+	case "DataRow":
+		msg := &DataRow{}
+		msg.Values = p.lengthPrefixedValues()
+		return msg, p.finish()
+	case "EmptyQueryResponse":
+		return &EmptyQueryResponse{}, nil
+	case "ErrorResponse":
+		msg := &ErrorResponse{}
+		return msg, p.parseErrorNoticeFields(msg)
+	case "FunctionCallResponse":
+		msg := &FunctionCallResponse{}
+		if p.done() {
+			return msg, nil
+		}
+		n := p.int(32)
+		if n >= 0 {
+			msg.Result = p.nchar(int(n))
+		}
+		return msg, p.finish()
+	case "NoData":
+		return &NoData{}, nil
+	case "NoticeResponse":
+		msg := &NoticeResponse{}
+		return msg, p.parseErrorNoticeFields((*ErrorResponse)(msg))
+	case "NotificationResponse":
+		msg := &NotificationResponse{}
+		msg.PID = uint32(p.int(32))
+		msg.Channel = p.str()
+		msg.Payload = p.strToEnd()
+		return msg, p.finish()
+	case "ParameterDescription":
+		msg := &ParameterDescription{}
+		if p.done() {
+			return msg, nil
+		}
+		n := p.int(16)
+		for i := int64(0); i < n && p.err == nil; i++ {
+			msg.ParameterOIDs = append(msg.ParameterOIDs, uint32(p.int(32)))
+		}
+		return msg, p.finish()
+	case "ParameterStatus":
+		msg := &ParameterStatus{}
+		msg.Name = p.str()
+		msg.Value = p.strToEnd()
+		return msg, p.finish()
+	case "ParseComplete":
+		return &ParseComplete{}, nil
+	case "PortalSuspended":
+		return &PortalSuspended{}, nil
+	case "ReadyForQuery":
+		msg := &ReadyForQuery{}
+		msg.TxStatus = p.byte1()
+		return msg, p.finish()
+	case "RowDescription":
+		msg := &RowDescription{}
+		n := p.int(16)
+		for i := int64(0); i < n && p.err == nil; i++ {
+			fd := FieldDescription{}
+			fd.Name = []byte(p.str())
+			fd.TableOID = uint32(p.int(32))
+			fd.TableAttributeNumber = uint16(p.int(16))
+			fd.DataTypeOID = uint32(p.int(32))
+			fd.DataTypeSize = int16(p.int(16))
+			fd.TypeModifier = int32(p.int(32))
+			fd.Format = int16(p.int(16))
+			msg.Fields = append(msg.Fields, fd)
+		}
+		return msg, p.finish()
+	default:
+		return nil, errors.New("unknown backend message type")
+	}
+}
+
+func (p *traceDetailsParser) parseBind() (*Bind, error) {
+	msg := &Bind{}
+	msg.DestinationPortal = p.str()
+	msg.PreparedStatement = p.str()
+	msg.ParameterFormatCodes = p.int16List()
+
+	n := p.int(16)
+	if n > 0 && p.peek() == '\'' {
+		// Older versions of pgx wrote the parameters without their lengths.
+		for i := int64(0); i < n && p.err == nil; i++ {
+			msg.Parameters = append(msg.Parameters, p.nchar(-1))
+		}
+	} else {
+		for i := int64(0); i < n && p.err == nil; i++ {
+			msg.Parameters = append(msg.Parameters, p.lengthPrefixedValue())
+		}
+	}
+
+	msg.ResultFormatCodes = p.int16List()
+	return msg, p.finish()
+}
+
+func (p *traceDetailsParser) parseParse() (*Parse, error) {
+	msg := &Parse{}
+	msg.Name = p.str()
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// The query is followed only by integers so the last double quote terminates it.
+	rest := p.src[p.rp:]
+	end := strings.LastIndexByte(rest, '"')
+	if !strings.HasPrefix(rest, ` "`) || end < 1 {
+		return nil, errors.New("invalid query")
+	}
+	msg.Query = rest[2:end]
+	p.rp += end + 1
+
+	n := p.int(16)
+	for i := int64(0); i < n && p.err == nil; i++ {
+		msg.ParameterOIDs = append(msg.ParameterOIDs, uint32(p.int(32)))
+	}
+	return msg, p.finish()
+}
+
+// parseErrorNoticeFields parses the fields written by pqTraceOutputErrorNotice in libpq and decodes them into msg.
+func (p *traceDetailsParser) parseErrorNoticeFields(msg *ErrorResponse) error {
+	if p.done() {
+		return nil
+	}
+
+	var buf []byte
+	for p.err == nil {
+		field := p.byte1()
+		buf = append(buf, field)
+		if field == 0 || p.done() {
+			break
+		}
+		buf = append(buf, p.strTerminatedBy(errorNoticeFieldStringEnd)...)
+		buf = append(buf, 0)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if len(buf) == 0 || buf[len(buf)-1] != 0 {
+		buf = append(buf, 0)
+	}
+
+	err := p.finish()
+	if err != nil {
+		return err
+	}
+	return msg.Decode(buf)
+}
+
+func (p *traceDetailsParser) done() bool {
+	return strings.TrimSpace(p.src[p.rp:]) == ""
+}
+
+func (p *traceDetailsParser) finish() error {
+	if p.err != nil {
+		return p.err
+	}
+	if !p.done() {
+		return fmt.Errorf("unexpected trailing data: %q", p.src[p.rp:])
+	}
+	return nil
+}
+
+func (p *traceDetailsParser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// field reads the space that precedes every field.
+func (p *traceDetailsParser) field() bool {
+	if p.err != nil {
+		return false
+	}
+	if p.rp >= len(p.src) {
+		p.fail(errTraceDetailsMissing)
+		return false
+	}
+	if p.src[p.rp] != ' ' {
+		p.fail(fmt.Errorf("expected space at offset %d", p.rp))
+		return false
+	}
+	p.rp++
+	return true
+}
+
+// peek returns the first byte of the next field or 0 if there are no more fields.
+func (p *traceDetailsParser) peek() byte {
+	if p.rp+1 < len(p.src) {
+		return p.src[p.rp+1]
+	}
+	return 0
+}
+
+func (p *traceDetailsParser) peekSuppressed() bool {
+	return strings.HasPrefix(p.src[p.rp:], " NNNN")
+}
+
+func (p *traceDetailsParser) suppressed() {
+	if !p.field() {
+		return
+	}
+	if !strings.HasPrefix(p.src[p.rp:], "NNNN") {
+		p.fail(errors.New("expected NNNN"))
+		return
+	}
+	p.rp += 4
+}
+
+func (p *traceDetailsParser) int(bitSize int) int64 {
+	if !p.field() {
+		return 0
+	}
+	end := p.rp
+	for end < len(p.src) && (p.src[end] == '-' || (p.src[end] >= '0' && p.src[end] <= '9')) {
+		end++
+	}
+	n, err := strconv.ParseInt(p.src[p.rp:end], 10, bitSize+1)
+	if err != nil {
+		p.fail(err)
+		return 0
+	}
+	p.rp = end
+	return n
+}
+
+// int8 reads a value written as either an integer or a hex-escaped byte.
+func (p *traceDetailsParser) int8() byte {
+	if strings.HasPrefix(p.src[p.rp:], ` \x`) {
+		return p.byte1()
+	}
+	return byte(p.int(8))
+}
+
+func (p *traceDetailsParser) int16List() []int16 {
+	n := p.int(16)
+	var values []int16
+	for i := int64(0); i < n && p.err == nil; i++ {
+		values = append(values, int16(p.int(16)))
+	}
+	return values
+}
+
+func (p *traceDetailsParser) uint16List() []uint16 {
+	n := p.int(16)
+	var values []uint16
+	for i := int64(0); i < n && p.err == nil; i++ {
+		values = append(values, uint16(p.int(16)))
+	}
+	return values
+}
+
+func (p *traceDetailsParser) byte1() byte {
+	if !p.field() {
+		return 0
+	}
+	if p.rp >= len(p.src) {
+		p.fail(errTraceDetailsMissing)
+		return 0
+	}
+	if strings.HasPrefix(p.src[p.rp:], `\x`) && p.rp+4 <= len(p.src) {
+		b, err := strconv.ParseUint(p.src[p.rp+2:p.rp+4], 16, 8)
+		if err == nil {
+			p.rp += 4
+			return byte(b)
+		}
+	}
+	b := p.src[p.rp]
+	p.rp++
+	return b
+}
+
+// str reads a double-quoted string. The string is terminated by the first double quote that is followed by a space or
+// the end of the details.
+func (p *traceDetailsParser) str() string {
+	return p.strTerminatedBy(func(rest string) bool {
+		return rest == "" || rest[0] == ' '
+	})
+}
+
+// errorNoticeFieldStringEnd reports whether rest follows the end of an ErrorResponse or NoticeResponse field value.
+// Field values frequently contain quoted identifiers so only a following field type or the terminator ends a value.
+func errorNoticeFieldStringEnd(rest string) bool {
+	return rest == "" || strings.HasPrefix(rest, ` \x00`) || (len(rest) >= 4 && rest[0] == ' ' && rest[2] == ' ' && rest[3] == '"')
+}
+
+// strTerminatedBy reads a double-quoted string. The string is terminated by the first double quote where isEnd
+// returns true for the remaining details.
+func (p *traceDetailsParser) strTerminatedBy(isEnd func(rest string) bool) string {
+	if !p.field() {
+		return ""
+	}
+	if p.rp >= len(p.src) || p.src[p.rp] != '"' {
+		p.fail(fmt.Errorf("expected double-quoted string at offset %d", p.rp))
+		return ""
+	}
+	start := p.rp + 1
+	for i := start; i < len(p.src); i++ {
+		if p.src[i] == '"' && isEnd(p.src[i+1:]) {
+			p.rp = i + 1
+			return p.src[start:i]
+		}
+	}
+	p.fail(errors.New("unterminated double-quoted string"))
+	return ""
+}
+
+// strToEnd reads a double-quoted string that is the last field.
+func (p *traceDetailsParser) strToEnd() string {
+	if !p.field() {
+		return ""
+	}
+	rest := strings.TrimRight(p.src[p.rp:], " ")
+	if len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+		p.fail(fmt.Errorf("expected double-quoted string at offset %d", p.rp))
+		return ""
+	}
+	p.rp = len(p.src)
+	return rest[1 : len(rest)-1]
+}
+
+func (p *traceDetailsParser) lengthPrefixedValue() []byte {
+	n := p.int(32)
+	if n < 0 {
+		return nil
+	}
+	return p.nchar(int(n))
+}
+
+func (p *traceDetailsParser) lengthPrefixedValues() [][]byte {
+	n := p.int(16)
+	var values [][]byte
+	for i := int64(0); i < n && p.err == nil; i++ {
+		values = append(values, p.lengthPrefixedValue())
+	}
+	return values
+}
+
+// nchar reads a single-quoted string of n bytes where non-printable bytes are hex-escaped. If n is negative the string
+// is terminated by the first single quote that is followed by a space or the end of the details.
+func (p *traceDetailsParser) nchar(n int) []byte {
+	if !p.field() {
+		return nil
+	}
+	if p.rp >= len(p.src) || p.src[p.rp] != '\'' {
+		p.fail(fmt.Errorf("expected single-quoted string at offset %d", p.rp))
+		return nil
+	}
+	p.rp++
+
+	buf := []byte{}
+	for {
+		if n >= 0 && len(buf) == n {
+			if p.rp >= len(p.src) || p.src[p.rp] != '\'' {
+				p.fail(fmt.Errorf("expected end of single-quoted string at offset %d", p.rp))
+				return nil
+			}
+			p.rp++
+			return buf
+		}
+		if p.rp >= len(p.src) {
+			p.fail(errors.New("unterminated single-quoted string"))
+			return nil
+		}
+		if n < 0 && p.src[p.rp] == '\'' && (p.rp+1 == len(p.src) || p.src[p.rp+1] == ' ') {
+			p.rp++
+			return buf
+		}
+
+		if strings.HasPrefix(p.src[p.rp:], `\x`) && p.rp+4 <= len(p.src) {
+			b, err := strconv.ParseUint(p.src[p.rp+2:p.rp+4], 16, 8)
+			if err == nil {
+				buf = append(buf, byte(b))
+				p.rp += 4
+				continue
+			}
+		}
+		buf = append(buf, p.src[p.rp])
+		p.rp++
+	}
+}
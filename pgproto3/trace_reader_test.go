@@ -0,0 +1,193 @@
+package pgproto3_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceReaderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	frontendMessages := []pgproto3.FrontendMessage{
+		&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersionNumber, Parameters: map[string]string{"user": "jack", "database": "mydb"}},
+		&pgproto3.Parse{Name: "ps1", Query: "select $1::text, \"quoted\" from t\nwhere x = 'a b'", ParameterOIDs: []uint32{25}},
+		&pgproto3.Bind{PreparedStatement: "ps1", ParameterFormatCodes: []int16{1}, Parameters: [][]byte{{0, 1, 'a', '\'', ' ', 255}, nil, {}}, ResultFormatCodes: []int16{0, 1}},
+		&pgproto3.Describe{ObjectType: 'P', Name: ""},
+		&pgproto3.Execute{Portal: "", MaxRows: 10},
+		&pgproto3.Close{ObjectType: 'S', Name: "ps1"},
+		&pgproto3.FunctionCall{Function: 42, ArgFormatCodes: []uint16{1}, Arguments: [][]byte{{1, 2}, nil}, ResultFormatCode: 1},
+		&pgproto3.Flush{},
+		&pgproto3.Sync{},
+		&pgproto3.Query{String: `select 'a" b'`},
+		&pgproto3.CopyFail{Message: "oops"},
+		&pgproto3.CancelRequest{ProcessID: 123, SecretKey: 456},
+		&pgproto3.Terminate{},
+	}
+
+	backendMessages := []pgproto3.BackendMessage{
+		&pgproto3.AuthenticationOk{},
+		&pgproto3.ParameterStatus{Name: "server_version", Value: "15.2"},
+		&pgproto3.BackendKeyData{ProcessID: 123, SecretKey: 456},
+		&pgproto3.ParseComplete{},
+		&pgproto3.ParameterDescription{ParameterOIDs: []uint32{25, 4294967295}},
+		&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+			{Name: []byte("n"), TableOID: 0, TableAttributeNumber: 0, DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1, Format: 0},
+			{Name: []byte("b"), TableOID: 16384, TableAttributeNumber: 2, DataTypeOID: 17, DataTypeSize: -1, TypeModifier: -1, Format: 1},
+		}},
+		&pgproto3.DataRow{Values: [][]byte{[]byte("1"), nil, {0x5, 'a', '\\', 'x'}}},
+		&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")},
+		&pgproto3.CopyInResponse{OverallFormat: 1, ColumnFormatCodes: []uint16{1, 1}},
+		&pgproto3.CopyOutResponse{OverallFormat: 0, ColumnFormatCodes: []uint16{0}},
+		&pgproto3.CopyBothResponse{OverallFormat: 0},
+		&pgproto3.CopyDone{},
+		&pgproto3.ErrorResponse{Severity: "ERROR", SeverityUnlocalized: "ERROR", Code: "42601", Message: "syntax error", Position: 8, File: "scan.l", Line: 1176, Routine: "scanner_yyerror"},
+		&pgproto3.NoticeResponse{Severity: "NOTICE", Code: "00000", Message: "hello"},
+		&pgproto3.NotificationResponse{PID: 1, Channel: "chan", Payload: "payload"},
+		&pgproto3.FunctionCallResponse{Result: []byte{1, 2}},
+		&pgproto3.FunctionCallResponse{},
+		&pgproto3.EmptyQueryResponse{},
+		&pgproto3.NoData{},
+		&pgproto3.PortalSuspended{},
+		&pgproto3.ReadyForQuery{TxStatus: 'T'},
+	}
+
+	traceOutput := &bytes.Buffer{}
+	frontend := pgproto3.NewFrontend(nil, io.Discard)
+	frontend.Trace(traceOutput, pgproto3.TracerOptions{})
+	for _, msg := range frontendMessages {
+		frontend.Send(msg)
+	}
+	backend := pgproto3.NewBackend(nil, io.Discard)
+	backend.Trace(traceOutput, pgproto3.TracerOptions{})
+	for _, msg := range backendMessages {
+		backend.Send(msg)
+	}
+
+	records, err := pgproto3.ReadTrace(traceOutput)
+	require.NoError(t, err)
+	require.Len(t, records, len(frontendMessages)+len(backendMessages))
+
+	for i, msg := range frontendMessages {
+		record := records[i]
+		assert.Equal(t, byte('F'), record.Sender)
+		assert.False(t, record.Time.IsZero())
+		assert.EqualValues(t, len(msg.Encode(nil)), record.EncodedLen)
+		assert.Equal(t, msg, record.Message)
+	}
+
+	for i, msg := range backendMessages {
+		record := records[len(frontendMessages)+i]
+		assert.Equal(t, byte('B'), record.Sender)
+		assert.EqualValues(t, len(msg.Encode(nil)), record.EncodedLen)
+		assert.Equal(t, msg, record.Message)
+	}
+}
+
+func TestTraceReaderRegressMode(t *testing.T) {
+	t.Parallel()
+
+	traceOutput := &bytes.Buffer{}
+	backend := pgproto3.NewBackend(nil, io.Discard)
+	backend.Trace(traceOutput, pgproto3.TracerOptions{SuppressTimestamps: true, RegressMode: true})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 123, SecretKey: 456})
+	backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "42601", Message: "syntax error", File: "scan.l", Line: 1176})
+
+	tr := pgproto3.NewTraceReader(traceOutput)
+
+	record, err := tr.Read()
+	require.NoError(t, err)
+	assert.True(t, record.Time.IsZero())
+	assert.Equal(t, &pgproto3.BackendKeyData{}, record.Message)
+
+	record, err = tr.Read()
+	require.NoError(t, err)
+	assert.Equal(t, &pgproto3.ErrorResponse{Severity: "ERROR", Code: "42601", Message: "syntax error", File: "SSSS"}, record.Message)
+
+	_, err = tr.Read()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestTraceReaderLibpqFormat(t *testing.T) {
+	t.Parallel()
+
+	// Output of PQtrace with PQTRACE_SUPPRESS_TIMESTAMPS for PQexecParams("select $1::int4", ...).
+	trace := `F	23	Parse	 "" "select $1::int4" 0
+F	19	Bind	 "" "" 0 1 1 '5' 1 0
+F	6	Describe	 P ""
+F	9	Execute	 "" 0
+F	4	Sync
+B	4	ParseComplete
+B	4	BindComplete
+B	29	RowDescription	 1 "int4" 0 0 23 4 -1 0
+B	11	DataRow	 1 1 '5'
+B	13	CommandComplete	 "SELECT 1"
+B	5	ReadyForQuery	 I
+F	4	Terminate
+`
+
+	records, err := pgproto3.ReadTrace(strings.NewReader(trace))
+	require.NoError(t, err)
+
+	expected := []pgproto3.Message{
+		&pgproto3.Parse{Query: "select $1::int4"},
+		&pgproto3.Bind{Parameters: [][]byte{[]byte("5")}, ResultFormatCodes: []int16{0}},
+		&pgproto3.Describe{ObjectType: 'P'},
+		&pgproto3.Execute{},
+		&pgproto3.Sync{},
+		&pgproto3.ParseComplete{},
+		&pgproto3.BindComplete{},
+		&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("int4"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1}}},
+		&pgproto3.DataRow{Values: [][]byte{[]byte("5")}},
+		&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")},
+		&pgproto3.ReadyForQuery{TxStatus: 'I'},
+		&pgproto3.Terminate{},
+	}
+
+	require.Len(t, records, len(expected))
+	for i, msg := range expected {
+		assert.Equal(t, msg, records[i].Message)
+		assert.EqualValues(t, len(msg.Encode(nil)), records[i].EncodedLen)
+	}
+}
+
+func TestTraceReaderLibpqErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	trace := "2023-05-01 10:11:12.123456\tB\t103\tErrorResponse\t S \"ERROR\" V \"ERROR\" C \"42P01\" M \"relation \"foo\" does not exist\" P \"15\" F \"parse_relation.c\" L \"1392\" R \"parserOpenTable\" \\x00\n"
+
+	records, err := pgproto3.ReadTrace(strings.NewReader(trace))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, 2023, records[0].Time.Year())
+	assert.Equal(t, 123456000, records[0].Time.Nanosecond())
+	assert.Equal(t, &pgproto3.ErrorResponse{
+		Severity:            "ERROR",
+		SeverityUnlocalized: "ERROR",
+		Code:                "42P01",
+		Message:             `relation "foo" does not exist`,
+		Position:            15,
+		File:                "parse_relation.c",
+		Line:                1392,
+		Routine:             "parserOpenTable",
+	}, records[0].Message)
+}
+
+func TestTraceReaderInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := pgproto3.ReadTrace(strings.NewReader("F\tUnknown\t5\n"))
+	require.ErrorContains(t, err, "trace line 1")
+
+	_, err = pgproto3.ReadTrace(strings.NewReader("not a trace\n"))
+	require.ErrorContains(t, err, "trace line 1")
+
+	_, err = pgproto3.ReadTrace(strings.NewReader("B\tDataRow\t12\t 1 1\n"))
+	require.Error(t, err)
+}
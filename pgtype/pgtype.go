@@ -245,6 +245,30 @@ func NewMap() *Map {
 	}
 }
 
+// Copy returns a copy of m with the same registered types and wrap functions. The copy memoizes plans separately, so m
+// and the copy can be used concurrently from different goroutines.
+func (m *Map) Copy() *Map {
+	newMap := NewMap()
+
+	for oid, t := range m.oidToType {
+		newMap.oidToType[oid] = t
+	}
+	for name, t := range m.nameToType {
+		newMap.nameToType[name] = t
+	}
+	for reflectType, name := range m.reflectTypeToName {
+		newMap.reflectTypeToName[reflectType] = name
+	}
+	for oid, formatCode := range m.oidToFormatCode {
+		newMap.oidToFormatCode[oid] = formatCode
+	}
+
+	newMap.TryWrapEncodePlanFuncs = append([]TryWrapEncodePlanFunc(nil), m.TryWrapEncodePlanFuncs...)
+	newMap.TryWrapScanPlanFuncs = append([]TryWrapScanPlanFunc(nil), m.TryWrapScanPlanFuncs...)
+
+	return newMap
+}
+
 // RegisterType registers a data type with the Map. t must not be mutated after it is registered.
 func (m *Map) RegisterType(t *Type) {
 	m.oidToType[t.OID] = t
@@ -130,6 +130,23 @@ func (f driverValuerFunc) Value() (driver.Value, error) {
 	return f()
 }
 
+func TestMapCopy(t *testing.T) {
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "mytext", OID: 999999, Codec: pgtype.TextCodec{}})
+
+	c := m.Copy()
+	_, ok := c.TypeForName("mytext")
+	require.True(t, ok)
+
+	c.RegisterType(&pgtype.Type{Name: "othertext", OID: 999998, Codec: pgtype.TextCodec{}})
+	_, ok = m.TypeForName("othertext")
+	require.False(t, ok)
+
+	buf, err := c.Encode(999999, pgtype.TextFormatCode, "foo", nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), buf)
+}
+
 func TestMapScanNilIsNoOp(t *testing.T) {
 	m := pgtype.NewMap()
 
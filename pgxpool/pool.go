@@ -93,6 +93,8 @@ type Pool struct {
 	maxConnIdleTime       time.Duration
 	healthCheckPeriod     time.Duration
 
+	descriptionCache *pgx.SharedDescriptionCache
+
 	healthCheckChan chan struct{}
 
 	closeOnce sync.Once
@@ -144,6 +146,12 @@ type Config struct {
 	// HealthCheckPeriod is the duration between checks of the health of idle connections.
 	HealthCheckPeriod time.Duration
 
+	// ShareDescriptionCache makes all connections of the pool share a single description cache for queries executed
+	// with QueryExecModeCacheDescribe. A statement is then described once for the pool instead of once per connection.
+	// The capacity of the shared cache is ConnConfig.DescriptionCacheCapacity. It is ignored if
+	// ConnConfig.DescriptionCache is set. See pgx.SharedDescriptionCache.
+	ShareDescriptionCache bool
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -195,12 +203,19 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		closeChan:             make(chan struct{}),
 	}
 
+	if config.ShareDescriptionCache && config.ConnConfig.DescriptionCache == nil && config.ConnConfig.DescriptionCacheCapacity > 0 {
+		p.descriptionCache = pgx.NewSharedDescriptionCache(config.ConnConfig.DescriptionCacheCapacity)
+	}
+
 	var err error
 	p.p, err = puddle.NewPool(
 		&puddle.Config[*connResource]{
 			Constructor: func(ctx context.Context) (*connResource, error) {
 				atomic.AddInt64(&p.newConnsCount, 1)
 				connConfig := p.config.ConnConfig.Copy()
+				if connConfig.DescriptionCache == nil {
+					connConfig.DescriptionCache = p.descriptionCache
+				}
 
 				// Connection will continue in background even if Acquire is canceled. Ensure that a connect won't hang forever.
 				if connConfig.ConnectTimeout <= 0 {
@@ -276,6 +291,7 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 //   - pool_max_conn_idle_time: duration string
 //   - pool_health_check_period: duration string
 //   - pool_max_conn_lifetime_jitter: duration string
+//   - pool_share_description_cache: boolean
 //
 // See Config for definitions of these arguments.
 //
@@ -365,6 +381,15 @@ func ParseConfig(connString string) (*Config, error) {
 		config.MaxConnLifetimeJitter = d
 	}
 
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_share_description_cache"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_share_description_cache")
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_share_description_cache: %w", err)
+		}
+		config.ShareDescriptionCache = b
+	}
+
 	return config, nil
 }
 
@@ -51,6 +51,56 @@ func TestParseConfigExtractsPoolArguments(t *testing.T) {
 	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_min_conns")
 }
 
+func TestParseConfigExtractsShareDescriptionCache(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("pool_share_description_cache=true")
+	require.NoError(t, err)
+	assert.True(t, config.ShareDescriptionCache)
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_share_description_cache")
+
+	_, err = pgxpool.ParseConfig("pool_share_description_cache=maybe")
+	require.Error(t, err)
+}
+
+func TestPoolShareDescriptionCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.ShareDescriptionCache = true
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c1, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer c1.Release()
+
+	c2, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer c2.Release()
+
+	require.NotNil(t, c1.Conn().Config().DescriptionCache)
+	require.Same(t, c1.Conn().Config().DescriptionCache, c2.Conn().Config().DescriptionCache)
+
+	var n int32
+	err = c1.QueryRow(ctx, "select $1::int4", 42).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
+	require.Equal(t, 1, c1.Conn().Config().DescriptionCache.Len())
+
+	err = c2.QueryRow(ctx, "select $1::int4", 43).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 43, n)
+	require.Equal(t, 1, c2.Conn().Config().DescriptionCache.Len())
+}
+
 func TestConstructorIgnoresContext(t *testing.T) {
 	t.Parallel()
 
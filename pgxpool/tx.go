@@ -9,13 +9,18 @@ import (
 
 // Tx represents a database transaction acquired from a Pool.
 type Tx struct {
-	t pgx.Tx
-	c *Conn
+	t       pgx.Tx
+	c       *Conn
+	cursors []*pgx.Cursor
 }
 
 // Begin starts a pseudo nested transaction implemented with a savepoint.
 func (tx *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
-	return tx.t.Begin(ctx)
+	t, err := tx.t.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &nestedTx{Tx: t, root: tx}, nil
 }
 
 // Commit commits the transaction and returns the associated connection back to the Pool. Commit will return ErrTxClosed
@@ -23,6 +28,7 @@ func (tx *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
 // (e.g. the transaction was already in a broken state) then ErrTxCommitRollback will be returned.
 func (tx *Tx) Commit(ctx context.Context) error {
 	err := tx.t.Commit(ctx)
+	tx.closeHeldCursors(ctx)
 	if tx.c != nil {
 		tx.c.Release()
 		tx.c = nil
@@ -35,6 +41,7 @@ func (tx *Tx) Commit(ctx context.Context) error {
 // tx.Commit() will be called first in a non-error condition.
 func (tx *Tx) Rollback(ctx context.Context) error {
 	err := tx.t.Rollback(ctx)
+	tx.cursors = nil
 	if tx.c != nil {
 		tx.c.Release()
 		tx.c = nil
@@ -42,6 +49,27 @@ func (tx *Tx) Rollback(ctx context.Context) error {
 	return err
 }
 
+// RegisterCursor implements pgx.CursorRegistry by forwarding to the underlying transaction. Cursors declared in tx are
+// closed when it ends. This includes cursors declared WITH HOLD because the connection is returned to the pool when tx
+// ends.
+func (tx *Tx) RegisterCursor(cursor *pgx.Cursor) {
+	if r, ok := tx.t.(pgx.CursorRegistry); ok {
+		r.RegisterCursor(cursor)
+	}
+	tx.cursors = append(tx.cursors, cursor)
+}
+
+// closeHeldCursors closes the cursors declared WITH HOLD that remained open after commit before the connection is
+// returned to the pool.
+func (tx *Tx) closeHeldCursors(ctx context.Context) {
+	for _, c := range tx.cursors {
+		// An error is ignored as the transaction has already ended. The connection is not returned to the pool if it is
+		// broken.
+		_ = c.Close(ctx)
+	}
+	tx.cursors = nil
+}
+
 func (tx *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
 	return tx.t.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
@@ -80,3 +108,27 @@ func (tx *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 func (tx *Tx) Conn() *pgx.Conn {
 	return tx.t.Conn()
 }
+
+// nestedTx is a pseudo nested transaction of a Tx. Cursors declared in it are also registered with the Tx so cursors
+// declared WITH HOLD are closed before the connection is returned to the pool.
+type nestedTx struct {
+	pgx.Tx
+	root *Tx
+}
+
+// Begin starts a pseudo nested transaction implemented with a savepoint.
+func (tx *nestedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	t, err := tx.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &nestedTx{Tx: t, root: tx.root}, nil
+}
+
+// RegisterCursor implements pgx.CursorRegistry by forwarding to the savepoint transaction and the Tx.
+func (tx *nestedTx) RegisterCursor(cursor *pgx.Cursor) {
+	if r, ok := tx.Tx.(pgx.CursorRegistry); ok {
+		r.RegisterCursor(cursor)
+	}
+	tx.root.cursors = append(tx.root.cursors, cursor)
+}
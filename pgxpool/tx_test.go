@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/require"
 )
@@ -94,3 +95,73 @@ func TestTxCopyFrom(t *testing.T) {
 
 	testCopyFrom(t, ctx, tx)
 }
+
+func TestTxCursorClosedAtTxEnd(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	cursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{}, "select 1")
+	require.NoError(t, err)
+	holdCursor, err := pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{Hold: true}, "select 2")
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Commit(ctx))
+	require.True(t, cursor.IsClosed())
+	require.True(t, holdCursor.IsClosed())
+
+	_, err = holdCursor.Fetch(ctx, 1)
+	require.ErrorIs(t, err, pgx.ErrCursorClosed)
+
+	tx, err = pool.Begin(ctx)
+	require.NoError(t, err)
+	cursor, err = pgx.DeclareCursor(ctx, tx, pgx.CursorOptions{}, "select 1")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+	require.True(t, cursor.IsClosed())
+}
+
+func TestTxNestedHoldCursorClosedAtTxEnd(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	nestedTx, err := tx.Begin(ctx)
+	require.NoError(t, err)
+
+	holdCursor, err := pgx.DeclareCursor(ctx, nestedTx, pgx.CursorOptions{Hold: true}, "select 1")
+	require.NoError(t, err)
+
+	require.NoError(t, nestedTx.Commit(ctx))
+	require.False(t, holdCursor.IsClosed())
+	require.NoError(t, tx.Commit(ctx))
+	require.True(t, holdCursor.IsClosed())
+
+	// The pool has a single connection so this is the connection the transaction used.
+	var n int
+	err = pool.QueryRow(ctx, "select count(*) from pg_cursors").Scan(&n)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
@@ -0,0 +1,516 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrPipelineQueryAborted is returned for a query sent in a pipeline when an earlier query between the same
+// synchronization points failed. The server skips all queries until the next synchronization point after an error.
+var ErrPipelineQueryAborted = errors.New("pipeline query aborted by earlier error")
+
+// Pipeline sends queries to the server without waiting for the results of earlier queries. Unlike Batch, queries can be
+// sent incrementally while the results of earlier queries are being read. Start a Pipeline with Conn.StartPipeline.
+//
+// Each call to SendQuery returns a *PipelineQuery that is used to read the results of that query. Results are received
+// in the order the queries were sent. Reading the results of a query discards the unread results of all earlier
+// queries. Rows returned by a previous PipelineQuery are closed when the results of a later query are read.
+//
+// Queries between synchronization points established with Sync are implicitly transactional unless explicit
+// transaction control statements are executed. If a query fails the server skips all remaining queries until the next
+// synchronization point. The results of the skipped queries are ErrPipelineQueryAborted.
+//
+// Each query is reported to the QueryTracer of the connection. The trace of a query starts when it is sent and ends
+// when its results have been read or discarded.
+//
+// Close must be called before the connection can be used again.
+type Pipeline struct {
+	ctx      context.Context
+	conn     *Conn
+	pipeline *pgconn.Pipeline
+
+	// pending contains the queries whose results have not been received yet in the order they were sent. A nil entry is
+	// a synchronization point.
+	pending   []*PipelineQuery
+	unsynced  bool
+	unflushed bool
+	aborted   bool
+
+	lastRows *baseRows
+
+	err    error
+	closed bool
+}
+
+// PipelineQuery is a query that has been sent in a Pipeline.
+type PipelineQuery struct {
+	p    *Pipeline
+	ctx  context.Context
+	sql  string
+	args []any
+
+	received   bool
+	commandTag pgconn.CommandTag
+	err        error
+	traceEnded bool
+}
+
+// StartPipeline starts a pipeline on c. ctx is in effect for the entire life of the Pipeline. Close must be called on
+// the returned Pipeline before c can be used again.
+//
+// Arguments are encoded using the statement description from the statement cache or description cache when
+// DefaultQueryExecMode is QueryExecModeCacheStatement or QueryExecModeCacheDescribe. A statement that is not yet
+// cached is prepared and cached when it is sent while no results are pending. Otherwise, and in all other query exec
+// modes, arguments are encoded as with QueryExecModeExec.
+func (c *Conn) StartPipeline(ctx context.Context) *Pipeline {
+	p := &Pipeline{ctx: ctx, conn: c}
+
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		p.err = err
+		p.closed = true
+		return p
+	}
+
+	p.pipeline = c.pgConn.StartPipeline(ctx)
+	return p
+}
+
+// SendQuery sends sql with args to the server and returns a *PipelineQuery to read the results. The query is not
+// necessarily written to the server until Flush or Sync is called or the results of the query are read. The only pgx
+// option argument that is supported is QueryRewriter.
+func (p *Pipeline) SendQuery(sql string, args ...any) *PipelineQuery {
+	pq := &PipelineQuery{p: p, ctx: p.ctx, sql: sql, args: args}
+
+	if p.conn.queryTracer != nil {
+		pq.ctx = p.conn.queryTracer.TraceQueryStart(pq.ctx, p.conn, TraceQueryStartData{SQL: sql, Args: args})
+	}
+
+	if p.closed {
+		pq.fail(p.closedError())
+		return pq
+	}
+
+	if len(args) > 0 {
+		if queryRewriter, ok := args[0].(QueryRewriter); ok {
+			var err error
+			pq.sql, pq.args, err = queryRewriter.RewriteQuery(pq.ctx, p.conn, sql, args[1:])
+			if err != nil {
+				pq.fail(fmt.Errorf("rewrite query failed: %w", err))
+				return pq
+			}
+		}
+	}
+
+	var err error
+	pq.sql, pq.args, err = p.conn.applyQueryRewriters(pq.ctx, p.conn.config.DefaultQueryExecMode, pq.sql, pq.args)
+	if err != nil {
+		pq.fail(fmt.Errorf("rewrite query failed: %w", err))
+		return pq
+	}
+
+	sd, err := p.getStatementDescription(pq.sql)
+	if err != nil {
+		pq.fail(err)
+		return pq
+	}
+
+	c := p.conn
+	err = c.eqb.Build(c.typeMap, sd, pq.args)
+	if err != nil {
+		pq.fail(err)
+		return pq
+	}
+
+	switch {
+	case sd == nil:
+		p.pipeline.SendQueryParams(pq.sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+	case sd.Name == "":
+		p.pipeline.SendQueryParams(pq.sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats)
+	default:
+		p.pipeline.SendQueryPrepared(sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
+	}
+	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
+
+	p.pending = append(p.pending, pq)
+	p.unsynced = true
+	p.unflushed = true
+
+	return pq
+}
+
+// getStatementDescription returns the statement description to use for sql. nil is returned if the arguments should
+// be encoded as with QueryExecModeExec.
+func (p *Pipeline) getStatementDescription(sql string) (*pgconn.StatementDescription, error) {
+	c := p.conn
+
+	if sd, ok := c.preparedStatements[sql]; ok {
+		return sd, nil
+	}
+
+	var sdCache stmtcache.Cache
+	var name string
+	switch c.config.DefaultQueryExecMode {
+	case QueryExecModeCacheStatement:
+		if c.statementCache == nil {
+			return nil, errDisabledStatementCache
+		}
+		sdCache = c.statementCache
+		name = stmtcache.StatementName(sql)
+	case QueryExecModeCacheDescribe:
+		if c.descriptionCache == nil {
+			return nil, errDisabledDescriptionCache
+		}
+		sdCache = c.descriptionCache
+	default:
+		return nil, nil
+	}
+
+	if sd := sdCache.Get(sql); sd != nil {
+		return sd, nil
+	}
+
+	// The description can only be read immediately when no earlier results are pending. If the current segment has
+	// been aborted the server would ignore the request.
+	if len(p.pending) > 0 || p.aborted {
+		return nil, nil
+	}
+
+	p.pipeline.SendPrepare(name, sql, nil)
+	p.pipeline.SendFlushRequest()
+	p.unsynced = true
+	err := p.pipeline.Flush()
+	if err != nil {
+		p.fatal(err)
+		return nil, err
+	}
+	p.unflushed = false
+
+	results, err := p.pipeline.GetResults()
+	if err != nil {
+		if !p.checkAborted(err) {
+			p.fatal(err)
+		}
+		return nil, err
+	}
+
+	sd, ok := results.(*pgconn.StatementDescription)
+	if !ok {
+		err = fmt.Errorf("expected statement description, got %T", results)
+		p.fatal(err)
+		return nil, err
+	}
+	sd.Name = name
+	sd.SQL = sql
+	sdCache.Put(sd)
+
+	return sd, nil
+}
+
+// Sync establishes a synchronization point and writes all sent queries to the server.
+func (p *Pipeline) Sync() error {
+	if p.closed {
+		return p.closedError()
+	}
+
+	err := p.pipeline.Sync()
+	if err != nil {
+		p.fatal(err)
+		return err
+	}
+
+	p.pending = append(p.pending, nil)
+	p.unsynced = false
+	p.unflushed = false
+
+	return nil
+}
+
+// Flush writes all sent queries to the server and requests the server to send their results without establishing a
+// synchronization point.
+func (p *Pipeline) Flush() error {
+	if p.closed {
+		return p.closedError()
+	}
+
+	if !p.unflushed {
+		return nil
+	}
+
+	p.pipeline.SendFlushRequest()
+	err := p.pipeline.Flush()
+	if err != nil {
+		p.fatal(err)
+		return err
+	}
+	p.unflushed = false
+
+	return nil
+}
+
+// Close reads and discards all unread results and ends the pipeline. Queries that have been sent since the last Sync
+// are synchronized first. It returns the first error encountered while reading results that had not been read. Any
+// fatal error will have closed the underlying connection.
+func (p *Pipeline) Close() error {
+	if p.closed {
+		return p.err
+	}
+
+	if p.unsynced {
+		err := p.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	p.closeLastRows()
+
+	var firstErr error
+	for len(p.pending) > 0 {
+		pq, err := p.receiveNext()
+		if err != nil {
+			return err
+		}
+		if pq != nil && firstErr == nil {
+			firstErr = pq.err
+		}
+	}
+
+	p.closed = true
+	err := p.pipeline.Close()
+	if err != nil {
+		p.err = err
+		return err
+	}
+
+	return firstErr
+}
+
+// receive receives the results of pq. The results of all earlier queries are discarded. If pq is a query returning
+// rows then its *pgconn.ResultReader is returned for the caller to read.
+func (p *Pipeline) receive(pq *PipelineQuery) (*pgconn.ResultReader, error) {
+	if p.closed {
+		return nil, p.closedError()
+	}
+
+	if p.unflushed {
+		err := p.Flush()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for len(p.pending) > 0 {
+		if p.pending[0] == pq {
+			p.pending = p.pending[1:]
+			pq.received = true
+			return p.receiveResultReader(pq)
+		}
+
+		_, err := p.receiveNext()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, errors.New("BUG: pipeline query not pending")
+}
+
+// receiveNext receives and discards the next pending result. It returns the *PipelineQuery whose results were
+// discarded or nil for a synchronization point.
+func (p *Pipeline) receiveNext() (*PipelineQuery, error) {
+	p.closeLastRows()
+
+	pq := p.pending[0]
+	p.pending = p.pending[1:]
+
+	if pq == nil {
+		results, err := p.pipeline.GetResults()
+		if err != nil {
+			p.fatal(err)
+			return nil, err
+		}
+		if _, ok := results.(*pgconn.PipelineSync); !ok {
+			err = fmt.Errorf("expected sync, got %T", results)
+			p.fatal(err)
+			return nil, err
+		}
+		p.aborted = false
+		return nil, nil
+	}
+
+	pq.received = true
+	rr, err := p.receiveResultReader(pq)
+	if err != nil {
+		pq.traceEnd(pq.err)
+		if p.closed {
+			return nil, err
+		}
+		return pq, nil
+	}
+
+	pq.commandTag, pq.err = rr.Close()
+	p.checkAborted(pq.err)
+	pq.traceEnd(pq.err)
+
+	return pq, nil
+}
+
+// receiveResultReader receives the *pgconn.ResultReader for pq. A query error is recorded in pq.
+func (p *Pipeline) receiveResultReader(pq *PipelineQuery) (*pgconn.ResultReader, error) {
+	p.closeLastRows()
+
+	if p.aborted {
+		pq.err = ErrPipelineQueryAborted
+		return nil, pq.err
+	}
+
+	results, err := p.pipeline.GetResults()
+	if err != nil {
+		if !p.checkAborted(err) {
+			p.fatal(err)
+		}
+		pq.err = err
+		return nil, err
+	}
+
+	rr, ok := results.(*pgconn.ResultReader)
+	if !ok {
+		err = fmt.Errorf("unexpected pipeline result: %T", results)
+		p.fatal(err)
+		pq.err = err
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+// closeLastRows closes the Rows of the last query whose results were read.
+func (p *Pipeline) closeLastRows() {
+	if p.lastRows == nil {
+		return
+	}
+
+	p.lastRows.Close()
+	p.checkAborted(p.lastRows.Err())
+	p.lastRows = nil
+}
+
+// checkAborted records that the server is skipping queries until the next synchronization point if err is an error
+// returned by the server. It reports whether err is an error returned by the server.
+func (p *Pipeline) checkAborted(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		p.aborted = true
+		return true
+	}
+	return false
+}
+
+// fatal closes the pipeline after an error that makes it impossible to continue.
+func (p *Pipeline) fatal(err error) {
+	if p.closed {
+		return
+	}
+	p.err = err
+	p.closed = true
+	p.pipeline.Close()
+	for _, pq := range p.pending {
+		if pq != nil {
+			pq.fail(err)
+		}
+	}
+	if !p.conn.pgConn.IsClosed() {
+		// The connection cannot be resynchronized with the server.
+		p.conn.die(err)
+	}
+}
+
+func (p *Pipeline) closedError() error {
+	if p.err != nil {
+		return p.err
+	}
+	return errors.New("pipeline closed")
+}
+
+// Rows reads the results of pq as if the query had been sent with Conn.Query. The unread results of all earlier
+// queries are discarded. The returned Rows must be closed or read to completion before the results of a later query
+// are read, otherwise it is closed automatically.
+func (pq *PipelineQuery) Rows() (Rows, error) {
+	p := pq.p
+	rows := p.conn.getRows(pq.ctx, pq.sql, pq.args)
+
+	// The trace of the query is ended when rows is closed unless it has already been ended.
+	if pq.traceEnded {
+		rows.queryTracer = nil
+	}
+	pq.traceEnded = true
+
+	if pq.received {
+		err := pq.err
+		if err == nil {
+			err = errors.New("pipeline query results already read")
+		}
+		rows.fatal(err)
+		return rows, err
+	}
+
+	rr, err := p.receive(pq)
+	if err != nil {
+		rows.fatal(err)
+		return rows, err
+	}
+
+	rows.resultReader = rr
+	p.lastRows = rows
+
+	return rows, nil
+}
+
+// QueryRow reads the results of pq as if the query had been sent with Conn.QueryRow.
+func (pq *PipelineQuery) QueryRow() Row {
+	rows, _ := pq.Rows()
+	return (*connRow)(rows.(*baseRows))
+}
+
+// Exec reads the results of pq as if the query had been sent with Conn.Exec. Any rows returned by the query are
+// discarded. Exec may be called again to get the same results.
+func (pq *PipelineQuery) Exec() (pgconn.CommandTag, error) {
+	if pq.received {
+		return pq.commandTag, pq.err
+	}
+
+	rr, err := pq.p.receive(pq)
+	if err != nil {
+		pq.traceEnd(err)
+		return pgconn.CommandTag{}, err
+	}
+
+	pq.commandTag, pq.err = rr.Close()
+	pq.p.checkAborted(pq.err)
+	pq.traceEnd(pq.err)
+
+	return pq.commandTag, pq.err
+}
+
+// fail records err as the result of pq.
+func (pq *PipelineQuery) fail(err error) {
+	pq.received = true
+	pq.err = err
+	pq.traceEnd(pq.err)
+}
+
+// traceEnd ends the trace of pq with its command tag and err. It does nothing if the trace has already been ended.
+func (pq *PipelineQuery) traceEnd(err error) {
+	if pq.traceEnded {
+		return
+	}
+	pq.traceEnded = true
+
+	if tracer := pq.p.conn.queryTracer; tracer != nil {
+		tracer.TraceQueryEnd(pq.ctx, pq.p.conn, TraceQueryEndData{CommandTag: pq.commandTag, Err: err})
+	}
+}
@@ -2,10 +2,14 @@ package pgx_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +81,218 @@ func TestPipelineWithoutPreparedOrDescribedStatements(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestPipeline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, "create temporary table pipeline_test(n int4 not null)")
+
+		p := conn.StartPipeline(ctx)
+
+		insert1 := p.SendQuery("insert into pipeline_test(n) values($1)", 1)
+		insert2 := p.SendQuery("insert into pipeline_test(n) values($1)", 2)
+		selectAll := p.SendQuery("select n from pipeline_test order by n")
+
+		ct, err := insert1.Exec()
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, ct.RowsAffected())
+
+		// More queries can be sent while results are still being read.
+		insert3 := p.SendQuery("insert into pipeline_test(n) values($1)", 3)
+		sum := p.SendQuery("select sum(n) from pipeline_test")
+		require.NoError(t, p.Sync())
+
+		ct, err = insert2.Exec()
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, ct.RowsAffected())
+
+		rows, err := selectAll.Rows()
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 2}, numbers)
+
+		_, err = insert3.Exec()
+		require.NoError(t, err)
+
+		var n int64
+		err = sum.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, 6, n)
+
+		require.NoError(t, p.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestPipelineReadingLaterResultDiscardsEarlierResults(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		p := conn.StartPipeline(ctx)
+
+		q1 := p.SendQuery("select 1")
+		q2 := p.SendQuery("select n from generate_series(1, $1::int) n", 10)
+		require.NoError(t, p.Sync())
+		q3 := p.SendQuery("select $1::text", "foo")
+
+		var s string
+		err := q3.QueryRow().Scan(&s)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", s)
+
+		ct, err := q1.Exec()
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1", ct.String())
+
+		_, err = q2.Rows()
+		require.Error(t, err)
+
+		require.NoError(t, p.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestPipelineQueryErrorAbortsUntilSync(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		p := conn.StartPipeline(ctx)
+
+		q1 := p.SendQuery("select 1")
+		q2 := p.SendQuery("select 1/(1-$1::int)", 1)
+		q3 := p.SendQuery("select 3")
+		require.NoError(t, p.Sync())
+		q4 := p.SendQuery("select 4")
+
+		_, err := q1.Exec()
+		require.NoError(t, err)
+
+		rows, err := q2.Rows()
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, rows.Err(), &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+
+		_, err = q3.Exec()
+		require.ErrorIs(t, err, pgx.ErrPipelineQueryAborted)
+
+		var n int32
+		err = q4.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, n)
+
+		require.NoError(t, p.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestPipelineCloseReturnsUnreadError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		p := conn.StartPipeline(ctx)
+
+		p.SendQuery("select 1")
+		p.SendQuery("select * from missing_table")
+
+		err := p.Close()
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, "42P01", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestPipelineTraced(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var started []string
+		ended := map[string]pgx.TraceQueryEndData{}
+		tracer.traceQueryStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+			started = append(started, data.SQL)
+			return context.WithValue(ctx, ctxKey("sql"), data.SQL)
+		}
+		tracer.traceQueryEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+			sql := ctx.Value(ctxKey("sql")).(string)
+			require.NotContains(t, ended, sql)
+			ended[sql] = data
+		}
+		defer func() {
+			tracer.traceQueryStart = nil
+			tracer.traceQueryEnd = nil
+		}()
+
+		p := conn.StartPipeline(ctx)
+
+		discarded := p.SendQuery("select 1")
+		queried := p.SendQuery("select n from generate_series(1, $1::int) n", 3)
+		executed := p.SendQuery("select 2")
+		failed := p.SendQuery("select 1/0")
+		aborted := p.SendQuery("select 3")
+		require.NoError(t, p.Sync())
+		unread := p.SendQuery("select 4")
+
+		rows, err := queried.Rows()
+		require.NoError(t, err)
+		_, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+
+		_, err = executed.Exec()
+		require.NoError(t, err)
+
+		_, err = failed.Exec()
+		require.Error(t, err)
+
+		_, err = aborted.Exec()
+		require.ErrorIs(t, err, pgx.ErrPipelineQueryAborted)
+
+		require.NoError(t, p.Close())
+
+		_, err = discarded.Exec()
+		require.NoError(t, err)
+		_, err = unread.Exec()
+		require.NoError(t, err)
+
+		require.Len(t, started, 6)
+		require.Len(t, ended, 6)
+		assert.Equal(t, "SELECT 1", ended["select 1"].CommandTag.String())
+		assert.Equal(t, "SELECT 3", ended["select n from generate_series(1, $1::int) n"].CommandTag.String())
+		assert.Equal(t, "SELECT 1", ended["select 2"].CommandTag.String())
+		assert.Error(t, ended["select 1/0"].Err)
+		assert.ErrorIs(t, ended["select 3"].Err, pgx.ErrPipelineQueryAborted)
+		assert.Equal(t, "SELECT 1", ended["select 4"].CommandTag.String())
+	})
+}
@@ -2011,6 +2011,143 @@ func TestQueryWithQueryRewriter(t *testing.T) {
 	})
 }
 
+func TestConnConfigQueryRewriters(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	var calls []string
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.QueryRewriters = []pgx.QueryRewriter{
+			pgx.QueryRewriterFunc(func(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+				calls = append(calls, "double")
+				newArgs := make([]any, len(args))
+				for i := range args {
+					if n, ok := args[i].(int); ok {
+						newArgs[i] = n * 2
+					} else {
+						newArgs[i] = args[i]
+					}
+				}
+				return sql, newArgs, nil
+			}),
+			pgx.QueryRewriterFunc(func(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+				calls = append(calls, "comment")
+				return "/* rewritten */ " + sql, args, nil
+			}),
+		}
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		calls = nil
+
+		var n int32
+		err := conn.QueryRow(ctx, "select @n::int4", pgx.NamedArgs{"n": 21}).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+		require.Equal(t, []string{"double", "comment"}, calls)
+
+		commandTag, err := conn.Exec(ctx, "select $1::int4", 1)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1", commandTag.String())
+
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4", 2)
+		batch.Queue("select $1::int4", 3)
+		br := conn.SendBatch(ctx, batch)
+		var a, b int32
+		require.NoError(t, br.QueryRow().Scan(&a))
+		require.NoError(t, br.QueryRow().Scan(&b))
+		require.NoError(t, br.Close())
+		require.EqualValues(t, 4, a)
+		require.EqualValues(t, 6, b)
+	})
+}
+
+func TestConnConfigQueryRewritersError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	rewriteErr := errors.New("rejected")
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.QueryRewriters = []pgx.QueryRewriter{
+			pgx.QueryRewriterFunc(func(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+				if strings.Contains(sql, "forbidden") {
+					return "", nil, rewriteErr
+				}
+				return sql, args, nil
+			}),
+		}
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "select 'forbidden'")
+		require.ErrorIs(t, err, rewriteErr)
+
+		rows, err := conn.Query(ctx, "select 'forbidden'")
+		require.ErrorIs(t, err, rewriteErr)
+		rows.Close()
+		require.ErrorIs(t, rows.Err(), rewriteErr)
+	})
+}
+
+func TestQueryWithQueryFetchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.Query(ctx, "select n from generate_series(1, $1::int) n", pgx.QueryFetchSize(7), 100)
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 100)
+		for i, n := range numbers {
+			require.EqualValues(t, i+1, n)
+		}
+		require.Equal(t, "SELECT 100", rows.CommandTag().String())
+
+		rows, err = conn.Query(ctx, "select n from generate_series(1, $1::int) n", pgx.QueryFetchSize(7), 100)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestQueryWithQueryFetchSizeAndSimpleProtocol(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Query(ctx, "select 1", pgx.QueryFetchSize(7))
+		require.ErrorContains(t, err, "QueryFetchSize")
+
+		ensureConnValid(t, conn)
+	})
+}
+
 // This example uses Query without using any helpers to read the results. Normally CollectRows, ForEachRow, or another
 // helper function should be used.
 func ExampleConn_Query() {
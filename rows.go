@@ -177,7 +177,8 @@ func (rows *baseRows) Close() {
 			sc.Invalidate(rows.sql)
 		}
 
-		if sc := rows.conn.descriptionCache; sc != nil {
+		// A description cache may be shared by many connections so it is only invalidated when the schema may have changed.
+		if sc := rows.conn.descriptionCache; sc != nil && isSchemaChangeError(rows.err) {
 			sc.Invalidate(rows.sql)
 		}
 	}
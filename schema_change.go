@@ -0,0 +1,59 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/internal/sanitize"
+)
+
+// DefaultSchemaChangeChannel is a channel name that can be used with InstallSchemaChangeNotifier and
+// ConnConfig.SchemaChangeChannel.
+const DefaultSchemaChangeChannel = "pgx_schema_change"
+
+// InstallSchemaChangeNotifier installs an event trigger in the current database that sends a notification on channel
+// at the end of every DDL command. The payload of the notification is the command tag (e.g. "ALTER TABLE").
+// Connections with ConnConfig.SchemaChangeChannel set to channel invalidate their statement and description caches
+// when they receive such a notification.
+//
+// The event trigger is named pgx_notify_schema_change and calls the function pgx_notify_schema_change. Both are
+// replaced if they already exist, so only one channel can be installed per database. Creating an event trigger
+// requires superuser privileges.
+//
+// A connection receives notifications only while it is used, so a query that is sent right after a schema change may
+// still use a stale cached statement or description. With QueryExecModeCacheStatement outside of a transaction such a
+// query is prepared again and retried automatically.
+func InstallSchemaChangeNotifier(ctx context.Context, conn *Conn, channel string) error {
+	sql := `create or replace function pgx_notify_schema_change() returns event_trigger language plpgsql as $$
+begin
+	perform pg_notify(` + sanitize.QuoteString(channel) + `, tg_tag);
+end
+$$;
+drop event trigger if exists pgx_notify_schema_change;
+create event trigger pgx_notify_schema_change on ddl_command_end execute procedure pgx_notify_schema_change();`
+
+	_, err := conn.Exec(ctx, sql)
+	return err
+}
+
+// UninstallSchemaChangeNotifier removes the event trigger and the function installed by InstallSchemaChangeNotifier.
+func UninstallSchemaChangeNotifier(ctx context.Context, conn *Conn) error {
+	_, err := conn.Exec(ctx, `drop event trigger if exists pgx_notify_schema_change;
+drop function if exists pgx_notify_schema_change();`)
+	return err
+}
+
+// invalidateCaches invalidates all entries of the statement and description caches and forgets the function OIDs cached
+// by FunctionCall. Which cached statements depend on a changed object cannot be reliably determined from their SQL so
+// all of them are invalidated. The invalidated prepared statements are deallocated before the next query outside of a
+// transaction.
+func (c *Conn) invalidateCaches() {
+	if c.statementCache != nil {
+		c.statementCache.InvalidateAll()
+	}
+
+	if c.descriptionCache != nil {
+		c.descriptionCache.InvalidateAll()
+	}
+
+	c.functionDescriptions = nil
+}
@@ -0,0 +1,63 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaChangeNotifierInvalidatesCaches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	err := pgx.InstallSchemaChangeNotifier(ctx, conn, pgx.DefaultSchemaChangeChannel)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42501" {
+		t.Skip("creating an event trigger requires superuser privileges")
+	}
+	require.NoError(t, err)
+	defer func() {
+		err := pgx.UninstallSchemaChangeNotifier(ctx, conn)
+		require.NoError(t, err)
+	}()
+
+	mustExec(t, conn, `drop table if exists pgx_schema_change_test`)
+	mustExec(t, conn, `create table pgx_schema_change_test(a int4)`)
+	defer mustExec(t, conn, `drop table pgx_schema_change_test`)
+	mustExec(t, conn, `insert into pgx_schema_change_test values (1)`)
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+	config.SchemaChangeChannel = pgx.DefaultSchemaChangeChannel
+	listenConn := mustConnect(t, config)
+	defer closeConn(t, listenConn)
+
+	rows, err := listenConn.Query(ctx, `select * from pgx_schema_change_test where a = $1`, 1)
+	require.NoError(t, err)
+	values, err := pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"a": int32(1)}}, values)
+
+	mustExec(t, conn, `alter table pgx_schema_change_test add column b text default 'x'`)
+
+	// Receive the notification.
+	_, err = listenConn.Exec(ctx, `select 1`)
+	require.NoError(t, err)
+
+	rows, err = listenConn.Query(ctx, `select * from pgx_schema_change_test where a = $1`, 1)
+	require.NoError(t, err)
+	values, err = pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"a": int32(1), "b": "x"}}, values)
+
+	ensureConnValid(t, listenConn)
+}
@@ -0,0 +1,152 @@
+// Package sqlcommenter appends sqlcommenter comments with tags from the context to queries.
+//
+// The comments make it possible to correlate server logs and pg_stat_statements with application traces. The format
+// follows the sqlcommenter specification: https://google.github.io/sqlcommenter/spec/
+//
+//	config.QueryRewriters = append(config.QueryRewriters, &sqlcommenter.Commenter{})
+//
+//	ctx = sqlcommenter.WithTags(ctx, map[string]string{"route": "/users/:id"})
+//	rows, err := conn.Query(ctx, "select * from users where id = $1", id)
+//	// sends: select * from users where id = $1 /*route='%2Fusers%2F%3Aid'*/
+package sqlcommenter
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ctxKey struct{}
+
+// WithTags returns a copy of ctx with tags added to the tags already in ctx. A tag in tags replaces a tag with the same
+// key in ctx.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	parent := TagsFromContext(ctx)
+	merged := make(map[string]string, len(parent)+len(tags))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// TagsFromContext returns the tags added to ctx with WithTags. The returned map must not be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(ctxKey{}).(map[string]string)
+	return tags
+}
+
+// Commenter is a pgx.QueryRewriter that appends a sqlcommenter comment to each query. It is intended to be used in
+// pgx.ConnConfig.QueryRewriters.
+//
+// Queries that already contain a comment are not modified, as required by the specification.
+type Commenter struct {
+	// Tags returns additional tags for ctx, such as traceparent from the current trace. Tags added with WithTags take
+	// precedence. It may be nil.
+	Tags func(ctx context.Context) map[string]string
+
+	// CommentCachedStatements enables comments on queries executed with pgx.QueryExecModeCacheStatement or
+	// pgx.QueryExecModeCacheDescribe. The statement and description caches are keyed by SQL so a comment with values that
+	// vary per query, such as traceparent, makes every query a cache miss and evicts useful entries. In addition, a
+	// prepared statement keeps the comment of the query that prepared it. By default these queries are not commented.
+	// Only enable this if the tags have few distinct values.
+	CommentCachedStatements bool
+}
+
+// RewriteQuery implements the pgx.QueryRewriter interface.
+func (c *Commenter) RewriteQuery(ctx context.Context, conn *pgx.Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
+	if !c.CommentCachedStatements {
+		if mode, ok := pgx.QueryExecModeFromContext(ctx); ok && (mode == pgx.QueryExecModeCacheStatement || mode == pgx.QueryExecModeCacheDescribe) {
+			return sql, args, nil
+		}
+	}
+
+	var tags map[string]string
+	if c.Tags != nil {
+		tags = c.Tags(ctx)
+	}
+	if ctxTags := TagsFromContext(ctx); len(ctxTags) > 0 {
+		if len(tags) == 0 {
+			tags = ctxTags
+		} else {
+			merged := make(map[string]string, len(tags)+len(ctxTags))
+			for k, v := range tags {
+				merged[k] = v
+			}
+			for k, v := range ctxTags {
+				merged[k] = v
+			}
+			tags = merged
+		}
+	}
+
+	return AppendComment(sql, tags), args, nil
+}
+
+// AppendComment returns sql with a sqlcommenter comment for tags appended. The comment is inserted before a trailing
+// semicolon. sql is returned unchanged if tags is empty or sql already contains a comment.
+func AppendComment(sql string, tags map[string]string) string {
+	if len(tags) == 0 || strings.Contains(sql, "/*") || strings.Contains(sql, "--") {
+		return sql
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	trimmed := strings.TrimRight(sql, " \t\r\n")
+	semicolon := strings.HasSuffix(trimmed, ";")
+	if semicolon {
+		trimmed = strings.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(trimmed)
+	sb.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(escape(k))
+		sb.WriteString("='")
+		sb.WriteString(escape(tags[k]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+	if semicolon {
+		sb.WriteByte(';')
+	}
+
+	return sb.String()
+}
+
+// escape URL encodes s as JavaScript's encodeURIComponent does and then escapes single quotes with a backslash.
+func escape(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+			sb.WriteByte(b)
+		case strings.IndexByte("-_.!~*()", b) >= 0:
+			sb.WriteByte(b)
+		case b == '\'':
+			sb.WriteString(`\'`)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hex[b>>4])
+			sb.WriteByte(hex[b&0x0f])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,121 @@
+package sqlcommenter_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/jackc/pgx/v5/sqlcommenter"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultConnTestRunner pgxtest.ConnTestRunner
+
+func init() {
+	defaultConnTestRunner = pgxtest.DefaultConnTestRunner()
+	defaultConnTestRunner.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config, err := pgx.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+		require.NoError(t, err)
+		config.QueryRewriters = []pgx.QueryRewriter{&sqlcommenter.Commenter{}}
+		return config
+	}
+}
+
+func TestAppendComment(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		sql      string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			sql:      "select 1",
+			tags:     nil,
+			expected: "select 1",
+		},
+		{
+			sql:      "select 1",
+			tags:     map[string]string{"route": "/param*d", "action": "run", "framework": "pgx"},
+			expected: "select 1 /*action='run',framework='pgx',route='%2Fparam*d'*/",
+		},
+		{
+			sql:      "select 1;\n",
+			tags:     map[string]string{"a": "b"},
+			expected: "select 1 /*a='b'*/;",
+		},
+		{
+			sql:      "select 1",
+			tags:     map[string]string{"name'": "DROP TABLE FOO", "quote": "it's */"},
+			expected: `select 1 /*name\'='DROP%20TABLE%20FOO',quote='it\'s%20*%2F'*/`,
+		},
+		{
+			sql:      "select 1",
+			tags:     map[string]string{"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01"},
+			expected: "select 1 /*traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/",
+		},
+		{
+			sql:      "select 1 /* existing */",
+			tags:     map[string]string{"a": "b"},
+			expected: "select 1 /* existing */",
+		},
+		{
+			sql:      "select 1 -- existing",
+			tags:     map[string]string{"a": "b"},
+			expected: "select 1 -- existing",
+		},
+	} {
+		require.Equalf(t, tt.expected, sqlcommenter.AppendComment(tt.sql, tt.tags), "%d", i)
+	}
+}
+
+func TestWithTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := sqlcommenter.WithTags(context.Background(), map[string]string{"a": "1", "b": "2"})
+	ctx2 := sqlcommenter.WithTags(ctx, map[string]string{"b": "3"})
+
+	require.Equal(t, map[string]string{"a": "1", "b": "2"}, sqlcommenter.TagsFromContext(ctx))
+	require.Equal(t, map[string]string{"a": "1", "b": "3"}, sqlcommenter.TagsFromContext(ctx2))
+}
+
+func TestCommenterRewriteQuery(t *testing.T) {
+	t.Parallel()
+
+	c := &sqlcommenter.Commenter{
+		Tags: func(ctx context.Context) map[string]string {
+			return map[string]string{"traceparent": "00-01", "route": "default"}
+		},
+	}
+
+	ctx := sqlcommenter.WithTags(context.Background(), map[string]string{"route": "/users"})
+	sql, args, err := c.RewriteQuery(ctx, nil, "select $1::int4", []any{1})
+	require.NoError(t, err)
+	require.Equal(t, "select $1::int4 /*route='%2Fusers',traceparent='00-01'*/", sql)
+	require.Equal(t, []any{1}, args)
+}
+
+func TestCommenterComments(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	ctx = sqlcommenter.WithTags(ctx, map[string]string{"route": "/users"})
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var query string
+		err := conn.QueryRow(ctx, "select current_query() where $1::int4 = 1", 1).Scan(&query)
+		require.NoError(t, err)
+
+		mode := conn.Config().DefaultQueryExecMode
+		if mode == pgx.QueryExecModeCacheStatement || mode == pgx.QueryExecModeCacheDescribe {
+			require.Equal(t, "select current_query() where $1::int4 = 1", query)
+		} else {
+			require.Contains(t, query, "/*route='%2Fusers'*/")
+		}
+	})
+}
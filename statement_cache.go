@@ -0,0 +1,132 @@
+package pgx
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatementCache caches statement descriptions by SQL. It is used as the statement cache of a connection for
+// QueryExecModeCacheStatement and as the description cache for QueryExecModeCacheDescribe. A statement cache holds
+// prepared statements of a single connection so it must not be shared. A description cache may be shared if it is
+// safe for concurrent use.
+//
+// A cache may optionally implement Stats() StatementCacheStats. The stats are then available from
+// Conn.StatementCacheStats and Conn.DescriptionCacheStats.
+type StatementCache interface {
+	// Get returns the statement description for sql. Returns nil if not found.
+	Get(sql string) *pgconn.StatementDescription
+
+	// Put stores sd in the cache. Put panics if sd.SQL is "". Put does nothing if sd.SQL already exists in the cache.
+	Put(sd *pgconn.StatementDescription)
+
+	// Invalidate invalidates statement description identified by sql. Does nothing if not found.
+	Invalidate(sql string)
+
+	// InvalidateAll invalidates all statement descriptions.
+	InvalidateAll()
+
+	// HandleInvalidated returns a slice of all statement descriptions invalidated or evicted since the last call to
+	// HandleInvalidated. The connection deallocates the returned prepared statements. A statement description that was
+	// invalidated must not be stored again by Put before it is returned by HandleInvalidated.
+	HandleInvalidated() []*pgconn.StatementDescription
+
+	// Len returns the number of cached statement descriptions.
+	Len() int
+
+	// Cap returns the maximum number of cached statement descriptions.
+	Cap() int
+}
+
+// BuildStatementCacheFunc is a function that builds a StatementCache for conn.
+type BuildStatementCacheFunc func(conn *Conn) StatementCache
+
+// StatementCacheStats are the statistics of a StatementCache.
+type StatementCacheStats struct {
+	// Hits is the number of lookups that found a statement description.
+	Hits int64
+
+	// Misses is the number of lookups that did not find a statement description.
+	Misses int64
+
+	// Evictions is the number of statement descriptions removed because the cache was full or they expired.
+	Evictions int64
+}
+
+// StatementCacheOptions are the options of a StatementCache created by NewStatementCache.
+type StatementCacheOptions struct {
+	// Capacity is the maximum number of statement descriptions in the cache. The least recently used statement
+	// description is evicted when the cache is full. It must be greater than 0.
+	Capacity int
+
+	// MaxBytes is the maximum total length of the SQL of the statement descriptions in the cache. Server-side memory use
+	// of prepared statements grows with the length of their SQL so this can be used to limit it. If zero, only Capacity
+	// limits the size of the cache. A statement description whose SQL is longer than MaxBytes is still cached as the only
+	// entry.
+	MaxBytes int
+
+	// TTL is the duration after which a cached statement description expires, counted from when it was stored. An
+	// expired prepared statement is deallocated before the next query outside of a transaction. Until then it is still
+	// used. If zero, statement descriptions do not expire.
+	TTL time.Duration
+
+	// OnEvict is called with each statement description that is evicted because the cache is full or because it
+	// expired. It is called on the goroutine using the connection.
+	OnEvict func(sd *pgconn.StatementDescription)
+}
+
+// NewStatementCache returns a least recently used StatementCache configured by opts. It implements
+// Stats() StatementCacheStats. It is not safe for concurrent use.
+func NewStatementCache(opts StatementCacheOptions) StatementCache {
+	return &lruStatementCache{
+		LRUCache: stmtcache.NewLRUCacheWithOptions(stmtcache.Options{
+			Capacity: opts.Capacity,
+			MaxBytes: opts.MaxBytes,
+			TTL:      opts.TTL,
+			OnEvict:  opts.OnEvict,
+		}),
+	}
+}
+
+// lruStatementCache adapts stmtcache.LRUCache to provide StatementCacheStats.
+type lruStatementCache struct {
+	*stmtcache.LRUCache
+}
+
+func (c *lruStatementCache) Stats() StatementCacheStats {
+	return statementCacheStats(c.LRUCache.Stats())
+}
+
+func statementCacheStats(stats stmtcache.Stats) StatementCacheStats {
+	return StatementCacheStats{Hits: stats.Hits, Misses: stats.Misses, Evictions: stats.Evictions}
+}
+
+// cacheStats returns the stats of cache if it provides them.
+func cacheStats(cache stmtcache.Cache) StatementCacheStats {
+	if s, ok := cache.(interface{ Stats() StatementCacheStats }); ok {
+		return s.Stats()
+	}
+	if s, ok := cache.(interface{ Stats() stmtcache.Stats }); ok {
+		return statementCacheStats(s.Stats())
+	}
+	return StatementCacheStats{}
+}
+
+// StatementCacheStats returns the hits, misses, and evictions of the statement cache of the connection. It returns
+// zero stats if the statement cache is disabled or does not provide stats.
+func (c *Conn) StatementCacheStats() StatementCacheStats {
+	if c.statementCache == nil {
+		return StatementCacheStats{}
+	}
+	return cacheStats(c.statementCache)
+}
+
+// DescriptionCacheStats returns the hits, misses, and evictions of the description cache of the connection. If the
+// description cache is shared, the stats include lookups by all connections that share it.
+func (c *Conn) DescriptionCacheStats() StatementCacheStats {
+	if c.descriptionCache == nil {
+		return StatementCacheStats{}
+	}
+	return cacheStats(c.descriptionCache)
+}
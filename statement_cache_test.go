@@ -0,0 +1,112 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatementCacheStats(t *testing.T) {
+	t.Parallel()
+
+	sc := pgx.NewStatementCache(pgx.StatementCacheOptions{Capacity: 1})
+	sc.Put(&pgconn.StatementDescription{SQL: "a"})
+	require.NotNil(t, sc.Get("a"))
+	require.Nil(t, sc.Get("b"))
+	sc.Put(&pgconn.StatementDescription{SQL: "b"})
+
+	statsProvider, ok := sc.(interface {
+		Stats() pgx.StatementCacheStats
+	})
+	require.True(t, ok)
+	stats := statsProvider.Stats()
+	require.Equal(t, pgx.StatementCacheStats{Hits: 1, Misses: 1, Evictions: 1}, stats)
+}
+
+func TestConnBuildStatementCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	var evicted []string
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.BuildStatementCache = func(conn *pgx.Conn) pgx.StatementCache {
+		return pgx.NewStatementCache(pgx.StatementCacheOptions{
+			Capacity: 2,
+			OnEvict:  func(sd *pgconn.StatementDescription) { evicted = append(evicted, sd.SQL) },
+		})
+	}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	require.Equal(t, pgx.StatementCacheStats{}, conn.StatementCacheStats())
+
+	for _, sql := range []string{"select $1::int4", "select $1::int8", "select $1::int4", "select $1::int2"} {
+		var v any
+		err := conn.QueryRow(ctx, sql, 1).Scan(&v)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, pgx.StatementCacheStats{Hits: 1, Misses: 3, Evictions: 1}, conn.StatementCacheStats())
+	require.Equal(t, []string{"select $1::int8"}, evicted)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnBuildStatementCacheNilDisablesCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.BuildStatementCache = func(conn *pgx.Conn) pgx.StatementCache { return nil }
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	_, err := conn.Exec(ctx, "select $1::int4", pgx.QueryExecModeCacheStatement, 1)
+	require.Error(t, err)
+}
+
+func TestConnStatementCacheTTLWithBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	var evicted []string
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.BuildStatementCache = func(conn *pgx.Conn) pgx.StatementCache {
+		return pgx.NewStatementCache(pgx.StatementCacheOptions{
+			Capacity: 10,
+			TTL:      50 * time.Millisecond,
+			OnEvict:  func(sd *pgconn.StatementDescription) { evicted = append(evicted, sd.SQL) },
+		})
+	}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	for i := 0; i < 2; i++ {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4", i)
+		batch.Queue("select $1::int4", i+1)
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		// The cached statement expires before the next batch. It must be deallocated before the batch prepares it
+		// again with the same name.
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	require.Equal(t, []string{"select $1::int4"}, evicted)
+
+	ensureConnValid(t, conn)
+}
@@ -0,0 +1,191 @@
+package tracelog
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/internal/querytrace"
+	"github.com/jackc/pgx/v5/internal/sanitize"
+)
+
+// ExplainQuerier is the interface used by AutoExplain to run EXPLAIN. It is implemented by *pgx.Conn and
+// *pgxpool.Pool.
+type ExplainQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// AutoExplain configures TraceLog to capture the plans of slow queries. When a query succeeds after at least
+// Threshold, TraceLog runs it again as EXPLAIN (FORMAT JSON) with the same arguments. The plan is added to the log
+// entry of the query under the "plan" key and the entry is logged at LogLevelWarn instead of LogLevelInfo. If EXPLAIN
+// fails the error is added under the "planErr" key instead.
+//
+// The SQL and arguments are explained as they were sent to the server, after ConnConfig.QueryRewriters were applied.
+// EXPLAIN runs without the QueryRewriters of the connection that executes it so they are not applied twice.
+//
+// EXPLAIN runs synchronously when the query ends, i.e. inside Exec, QueryRow's Scan, or Rows.Close, so its latency is
+// added to the caller. Use SampleRate, MinInterval, and Timeout to bound this overhead.
+//
+// Only queries that do not modify the database are explained: SELECT, WITH, VALUES, and TABLE statements that do not
+// contain INSERT, UPDATE, DELETE, MERGE, INTO, row locking clauses, or calls to functions with side effects such as
+// nextval. Queries in a batch are not explained.
+type AutoExplain struct {
+	// Threshold is the minimum duration of a query to be explained.
+	Threshold time.Duration
+
+	// Querier runs EXPLAIN. It must be connected to the same database with the same search_path as the traced
+	// connections. If the tracer is used by more than one connection, it must be safe for concurrent use, e.g. a
+	// *pgxpool.Pool. If nil, EXPLAIN runs on the traced connection after the query completes. In that case queries in a
+	// transaction are not explained, because an error would abort the transaction, and Analyze is ignored.
+	Querier ExplainQuerier
+
+	// Analyze runs EXPLAIN ANALYZE which executes the query again and includes the actual row counts and timing. It is
+	// only used with Querier.
+	Analyze bool
+
+	// SampleRate is the fraction of the slow queries to explain between 0 and 1. If zero, every slow query is explained.
+	SampleRate float64
+
+	// MinInterval is the minimum time between two EXPLAINs. Slow queries within MinInterval of the previous EXPLAIN are
+	// logged without a plan. If zero, there is no limit.
+	MinInterval time.Duration
+
+	// Timeout limits the duration of EXPLAIN. If zero, only the context of the query applies. When EXPLAIN runs on the
+	// traced connection a timeout closes the connection.
+	Timeout time.Duration
+
+	// Exclude reports whether sql must not be explained in addition to the built-in checks. It may be nil.
+	Exclude func(sql string) bool
+
+	mu          sync.Mutex
+	lastExplain time.Time
+}
+
+// nonIdempotentWords are the keywords and functions that prevent a query from being explained.
+var nonIdempotentWords = map[string]struct{}{
+	"insert":               {},
+	"update":               {},
+	"delete":               {},
+	"merge":                {},
+	"into":                 {},
+	"share":                {},
+	"nextval":              {},
+	"setval":               {},
+	"lastval":              {},
+	"currval":              {},
+	"pg_notify":            {},
+	"pg_sleep":             {},
+	"pg_terminate_backend": {},
+	"pg_cancel_backend":    {},
+	"set_config":           {},
+	"dblink":               {},
+	"dblink_exec":          {},
+	"lo_import":            {},
+	"lo_export":            {},
+	"lo_unlink":            {},
+}
+
+// isExplainable reports whether sql is a single statement that only reads from the database.
+func isExplainable(sql string) bool {
+	fingerprint := sanitize.Fingerprint(sql)
+	if strings.Contains(fingerprint, ";") {
+		return false
+	}
+
+	words := strings.FieldsFunc(fingerprint, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "select", "with", "values", "table":
+	default:
+		return false
+	}
+
+	for _, w := range words {
+		if _, ok := nonIdempotentWords[w]; ok {
+			return false
+		}
+		if strings.HasPrefix(w, "pg_advisory") || strings.HasPrefix(w, "pg_try_advisory") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allow reports whether a slow query is sampled and not rate limited.
+func (ae *AutoExplain) allow() bool {
+	if ae.SampleRate > 0 && ae.SampleRate < 1 && rand.Float64() >= ae.SampleRate {
+		return false
+	}
+
+	if ae.MinInterval > 0 {
+		ae.mu.Lock()
+		defer ae.mu.Unlock()
+
+		now := time.Now()
+		if !ae.lastExplain.IsZero() && now.Sub(ae.lastExplain) < ae.MinInterval {
+			return false
+		}
+		ae.lastExplain = now
+	}
+
+	return true
+}
+
+// explain runs EXPLAIN for the query. explained is false if the query is not eligible.
+func (ae *AutoExplain) explain(ctx context.Context, conn *pgx.Conn, sql string, args []any) (plan string, explained bool, err error) {
+	querier := ae.Querier
+	analyze := ae.Analyze
+	if querier == nil {
+		if conn == nil || conn.IsClosed() || conn.PgConn().IsBusy() || conn.PgConn().TxStatus() != 'I' {
+			return "", false, nil
+		}
+		querier = conn
+		analyze = false
+	}
+
+	if !isExplainable(sql) || (ae.Exclude != nil && ae.Exclude(sql)) || !ae.allow() {
+		return "", false, nil
+	}
+
+	if ae.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ae.Timeout)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, tracelogAutoExplainCtxKey, true)
+	ctx = querytrace.WithSentQuery(ctx, nil)
+	ctx = querytrace.WithoutQueryRewriters(ctx)
+
+	explainSQL := "explain (format json) " + sql
+	if analyze {
+		explainSQL = "explain (analyze, format json) " + sql
+	}
+
+	err = querier.QueryRow(ctx, explainSQL, explainArgs(args)...).Scan(&plan)
+	return plan, true, err
+}
+
+// explainArgs returns args with the options that do not apply to EXPLAIN removed. EXPLAIN is always run with
+// pgx.QueryExecModeDescribeExec so it does not use the statement cache.
+func explainArgs(args []any) []any {
+	for len(args) > 0 {
+		switch args[0].(type) {
+		case pgx.QueryExecMode, pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+			args = args[1:]
+			continue
+		}
+		break
+	}
+
+	return append([]any{pgx.QueryExecModeDescribeExec}, args...)
+}
@@ -10,6 +10,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/internal/querytrace"
 )
 
 // LogLevel represents the pgx logging level. See LogLevel* constants for
@@ -117,11 +118,14 @@ func logQueryArgs(args []any) []any {
 	return logArgs
 }
 
-// TraceLog implements pgx.QueryTracer, pgx.BatchTracer, pgx.ConnectTracer, and pgx.CopyFromTracer. All fields are
-// required.
+// TraceLog implements pgx.QueryTracer, pgx.BatchTracer, pgx.ConnectTracer, and pgx.CopyFromTracer. Logger and
+// LogLevel are required.
 type TraceLog struct {
 	Logger   Logger
 	LogLevel LogLevel
+
+	// AutoExplain enables capturing the plans of slow queries. It may be nil.
+	AutoExplain *AutoExplain
 }
 
 type ctxKey int
@@ -133,23 +137,33 @@ const (
 	tracelogCopyFromCtxKey
 	tracelogConnectCtxKey
 	tracelogPrepareCtxKey
+	tracelogAutoExplainCtxKey
 )
 
 type traceQueryData struct {
 	startTime time.Time
 	sql       string
 	args      []any
+	sent      querytrace.Query // SQL and arguments after ConnConfig.QueryRewriters, only recorded for AutoExplain
 }
 
 func (tl *TraceLog) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	return context.WithValue(ctx, tracelogQueryCtxKey, &traceQueryData{
+	queryData := &traceQueryData{
 		startTime: time.Now(),
 		sql:       data.SQL,
 		args:      data.Args,
-	})
+	}
+	if tl.AutoExplain != nil {
+		ctx = querytrace.WithSentQuery(ctx, &queryData.sent)
+	}
+	return context.WithValue(ctx, tracelogQueryCtxKey, queryData)
 }
 
 func (tl *TraceLog) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if ctx.Value(tracelogAutoExplainCtxKey) != nil {
+		return
+	}
+
 	queryData := ctx.Value(tracelogQueryCtxKey).(*traceQueryData)
 
 	endTime := time.Now()
@@ -162,11 +176,35 @@ func (tl *TraceLog) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.
 		return
 	}
 
+	if tl.AutoExplain != nil && interval >= tl.AutoExplain.Threshold && tl.shouldLog(LogLevelWarn) {
+		sql, args := queryData.sql, queryData.args
+		if queryData.sent.SQL != "" {
+			sql, args = queryData.sent.SQL, queryData.sent.Args
+		}
+		plan, explained, err := tl.AutoExplain.explain(ctx, conn, sql, args)
+		if explained {
+			logData := map[string]any{"sql": queryData.sql, "args": logQueryArgs(queryData.args), "time": interval, "commandTag": data.CommandTag.String()}
+			if err != nil {
+				logData["planErr"] = err
+			} else {
+				logData["plan"] = plan
+			}
+			tl.log(ctx, conn, LogLevelWarn, "Query", logData)
+			return
+		}
+	}
+
 	if tl.shouldLog(LogLevelInfo) {
 		tl.log(ctx, conn, LogLevelInfo, "Query", map[string]any{"sql": queryData.sql, "args": logQueryArgs(queryData.args), "time": interval, "commandTag": data.CommandTag.String()})
 	}
 }
 
+func (tl *TraceLog) TraceQueryRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryRetryData) {
+	if tl.shouldLog(LogLevelWarn) {
+		tl.log(ctx, conn, LogLevelWarn, "Query retry", map[string]any{"sql": data.SQL, "err": data.Err})
+	}
+}
+
 type traceBatchData struct {
 	startTime time.Time
 }
@@ -298,6 +336,10 @@ func (tl *TraceLog) TracePrepareStart(ctx context.Context, _ *pgx.Conn, data pgx
 }
 
 func (tl *TraceLog) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	if ctx.Value(tracelogAutoExplainCtxKey) != nil {
+		return
+	}
+
 	prepareData := ctx.Value(tracelogPrepareCtxKey).(*tracePrepareData)
 
 	endTime := time.Now()
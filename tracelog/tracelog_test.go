@@ -3,6 +3,7 @@ package tracelog_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -457,3 +458,158 @@ func TestLogPrepare(t *testing.T) {
 		require.Equal(t, err, logger.logs[0].data["err"])
 	})
 }
+
+func TestLogQueryAutoExplain(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	logger := &testLogger{}
+	tracer := &tracelog.TraceLog{
+		Logger:      logger,
+		LogLevel:    tracelog.LogLevelTrace,
+		AutoExplain: &tracelog.AutoExplain{},
+	}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		logger.Clear() // Clear any logs written when establishing connection
+
+		var n int32
+		err := conn.QueryRow(ctx, `select $1::int4 + 1`, 1).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+
+		logs := logger.FilterByMsg("Query")
+		require.Len(t, logs, 1)
+		require.Equal(t, tracelog.LogLevelWarn, logs[0].lvl)
+		require.Equal(t, `select $1::int4 + 1`, logs[0].data["sql"])
+		require.Contains(t, logs[0].data["plan"], `"Plan"`)
+
+		logger.Clear()
+
+		_, err = conn.Exec(ctx, `select set_config('application_name', $1, false)`, "pgx_test")
+		require.NoError(t, err)
+
+		logs = logger.FilterByMsg("Query")
+		require.Len(t, logs, 1)
+		require.Equal(t, tracelog.LogLevelInfo, logs[0].lvl)
+		require.NotContains(t, logs[0].data, "plan")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		logger.Clear()
+
+		_, err = tx.Exec(ctx, `select 1`)
+		require.NoError(t, err)
+
+		logs = logger.FilterByMsg("Query")
+		require.Len(t, logs, 1)
+		require.Equal(t, tracelog.LogLevelInfo, logs[0].lvl)
+		require.NotContains(t, logs[0].data, "plan")
+	})
+}
+
+func TestLogQueryAutoExplainQueryRewriters(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	logger := &testLogger{}
+	tracer := &tracelog.TraceLog{
+		Logger:      logger,
+		LogLevel:    tracelog.LogLevelWarn,
+		AutoExplain: &tracelog.AutoExplain{},
+	}
+
+	rewriteCount := 0
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		config.QueryRewriters = []pgx.QueryRewriter{
+			pgx.QueryRewriterFunc(func(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+				if !strings.Contains(sql, "generate_series") {
+					return sql, args, nil
+				}
+				rewriteCount++
+				return sql + fmt.Sprintf(" where n <= $%d", len(args)+1), append(args, 2), nil
+			}),
+		}
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		logger.Clear() // Clear any logs written when establishing connection
+		rewriteCount = 0
+
+		rows, _ := conn.Query(ctx, `select n from generate_series(1, $1::int4) n`, 3)
+		ns, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2}, ns)
+		require.Equal(t, 1, rewriteCount)
+
+		logs := logger.FilterByMsg("Query")
+		require.Len(t, logs, 1)
+		require.Equal(t, `select n from generate_series(1, $1::int4) n`, logs[0].data["sql"])
+		require.NotContains(t, logs[0].data, "planErr")
+		require.Contains(t, logs[0].data["plan"], `"Filter"`)
+	})
+}
+
+func TestLogQueryAutoExplainWithQuerier(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	explainConn, err := pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer explainConn.Close(ctx)
+
+	logger := &testLogger{}
+	tracer := &tracelog.TraceLog{
+		Logger:   logger,
+		LogLevel: tracelog.LogLevelWarn,
+	}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tracer.AutoExplain = &tracelog.AutoExplain{
+			Querier:     explainConn,
+			Analyze:     true,
+			MinInterval: time.Hour,
+		}
+		logger.Clear() // Clear any logs written when establishing connection
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		for i := 0; i < 2; i++ {
+			_, err = tx.Exec(ctx, `select n from generate_series(1, $1::int4) n`, 3)
+			require.NoError(t, err)
+		}
+
+		logs := logger.FilterByMsg("Query")
+		require.Len(t, logs, 1)
+		require.Equal(t, tracelog.LogLevelWarn, logs[0].lvl)
+		require.Contains(t, logs[0].data["plan"], `"Actual Rows"`)
+	})
+}
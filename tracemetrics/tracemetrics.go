@@ -0,0 +1,340 @@
+// Package tracemetrics provides a tracer that collects client-side query metrics similar to pg_stat_statements.
+//
+// Queries are grouped by fingerprint: the SQL with literals and placeholders replaced, IN lists collapsed, and
+// comments removed. For each fingerprint the collector counts calls, errors, and rows and records a latency histogram.
+//
+//	collector := &tracemetrics.Collector{}
+//	config.Tracer = collector
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//		w.Header().Set("Content-Type", tracemetrics.PrometheusContentType)
+//		collector.WritePrometheus(w)
+//	})
+package tracemetrics
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/internal/sanitize"
+)
+
+// DefaultBuckets are the default upper bounds of the latency histogram buckets.
+var DefaultBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// DefaultMaxStatements is the default maximum number of distinct fingerprints tracked by a Collector.
+const DefaultMaxStatements = 1000
+
+// OtherFingerprint is the fingerprint that queries are counted under once a Collector tracks MaxStatements
+// fingerprints.
+const OtherFingerprint = "<other>"
+
+// PrometheusContentType is the content type of the output of WritePrometheus.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// maxFingerprintCache is the maximum number of SQL strings whose fingerprint is remembered.
+const maxFingerprintCache = 4096
+
+// Fingerprint returns the normalized form of sql that a Collector groups queries by. Queries that only differ in
+// literal values, placeholder numbers, the number of values in an IN list, comments, whitespace, or the case of
+// keywords and unquoted identifiers have the same fingerprint.
+func Fingerprint(sql string) string {
+	return sanitize.Fingerprint(sql)
+}
+
+// StatementStats are the metrics of all queries with the same fingerprint.
+type StatementStats struct {
+	// Fingerprint is the normalized SQL of the queries.
+	Fingerprint string
+
+	// Calls is the number of executions.
+	Calls int64
+
+	// Errors is the number of executions that failed.
+	Errors int64
+
+	// Rows is the total number of rows returned or affected as reported by the command tags.
+	Rows int64
+
+	// TotalTime is the sum of the latencies of all executions.
+	TotalTime time.Duration
+
+	// MinTime and MaxTime are the lowest and highest latency of an execution.
+	MinTime time.Duration
+	MaxTime time.Duration
+
+	// Buckets are the upper bounds of the latency histogram buckets.
+	Buckets []time.Duration
+
+	// BucketCounts are the cumulative number of executions with a latency less than or equal to the corresponding
+	// bucket upper bound. Executions slower than the last bucket are only included in Calls.
+	BucketCounts []int64
+}
+
+// MeanTime returns the average latency of an execution.
+func (s StatementStats) MeanTime() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalTime / time.Duration(s.Calls)
+}
+
+// Collector implements pgx.QueryTracer and pgx.BatchTracer. It collects metrics grouped by query fingerprint. It is
+// safe for concurrent use so one Collector can be used as the tracer of all connections of a pool. The zero value is
+// ready to use. The fields must not be changed after the Collector is first used.
+//
+// The latency of a query is measured from when it is sent until its results are read and closed. The queries of a
+// batch are sent together, so the latency of each query of a batch is measured from when the results of the previous
+// query were read.
+type Collector struct {
+	// Buckets are the upper bounds of the latency histogram buckets in ascending order. If nil, DefaultBuckets is used.
+	Buckets []time.Duration
+
+	// MaxStatements is the maximum number of distinct fingerprints tracked. Queries with other fingerprints are counted
+	// under OtherFingerprint. This bounds the memory use and the number of series exposed to Prometheus. If zero,
+	// DefaultMaxStatements is used.
+	MaxStatements int
+
+	// Namespace is the prefix of the Prometheus metric names. If empty, "pgx" is used.
+	Namespace string
+
+	mu           sync.Mutex
+	stats        map[string]*StatementStats
+	fingerprints map[string]string
+}
+
+type ctxKey int
+
+const (
+	_ ctxKey = iota
+	tracemetricsQueryCtxKey
+	tracemetricsBatchCtxKey
+)
+
+type traceQueryData struct {
+	startTime time.Time
+	sql       string
+}
+
+func (c *Collector) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, tracemetricsQueryCtxKey, &traceQueryData{
+		startTime: time.Now(),
+		sql:       data.SQL,
+	})
+}
+
+func (c *Collector) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	queryData := ctx.Value(tracemetricsQueryCtxKey).(*traceQueryData)
+	c.record(queryData.sql, time.Since(queryData.startTime), data.CommandTag.RowsAffected(), data.Err)
+}
+
+type traceBatchData struct {
+	lastTime time.Time
+}
+
+func (c *Collector) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, tracemetricsBatchCtxKey, &traceBatchData{
+		lastTime: time.Now(),
+	})
+}
+
+func (c *Collector) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	batchData := ctx.Value(tracemetricsBatchCtxKey).(*traceBatchData)
+	now := time.Now()
+	c.record(data.SQL, now.Sub(batchData.lastTime), data.CommandTag.RowsAffected(), data.Err)
+	batchData.lastTime = now
+}
+
+func (c *Collector) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+}
+
+func (c *Collector) buckets() []time.Duration {
+	if c.Buckets != nil {
+		return c.Buckets
+	}
+	return DefaultBuckets
+}
+
+func (c *Collector) record(sql string, elapsed time.Duration, rows int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil {
+		c.stats = make(map[string]*StatementStats)
+		c.fingerprints = make(map[string]string)
+	}
+
+	fingerprint, ok := c.fingerprints[sql]
+	if !ok {
+		fingerprint = sanitize.Fingerprint(sql)
+		if len(c.fingerprints) >= maxFingerprintCache {
+			c.fingerprints = make(map[string]string)
+		}
+		c.fingerprints[sql] = fingerprint
+	}
+
+	s, ok := c.stats[fingerprint]
+	if !ok {
+		maxStatements := c.MaxStatements
+		if maxStatements == 0 {
+			maxStatements = DefaultMaxStatements
+		}
+		if len(c.stats) >= maxStatements {
+			fingerprint = OtherFingerprint
+			s = c.stats[fingerprint]
+		}
+		if s == nil {
+			buckets := c.buckets()
+			s = &StatementStats{Fingerprint: fingerprint, Buckets: buckets, BucketCounts: make([]int64, len(buckets))}
+			c.stats[fingerprint] = s
+		}
+	}
+
+	s.Calls++
+	if err != nil {
+		s.Errors++
+	}
+	s.Rows += rows
+	s.TotalTime += elapsed
+	if s.Calls == 1 || elapsed < s.MinTime {
+		s.MinTime = elapsed
+	}
+	if elapsed > s.MaxTime {
+		s.MaxTime = elapsed
+	}
+	for i, b := range s.Buckets {
+		if elapsed <= b {
+			s.BucketCounts[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the metrics of each fingerprint sorted by fingerprint.
+func (c *Collector) Snapshot() []StatementStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make([]StatementStats, 0, len(c.stats))
+	for _, s := range c.stats {
+		sc := *s
+		sc.BucketCounts = append([]int64(nil), s.BucketCounts...)
+		snapshot = append(snapshot, sc)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Fingerprint < snapshot[j].Fingerprint })
+
+	return snapshot
+}
+
+// Reset discards all collected metrics.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats = nil
+	c.fingerprints = nil
+}
+
+// WritePrometheus writes the collected metrics to w in the Prometheus text exposition format. Each metric has a query
+// label with the fingerprint.
+//
+// The metrics are <namespace>_query_calls_total, <namespace>_query_errors_total, <namespace>_query_rows_total, and
+// the histogram <namespace>_query_duration_seconds.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	snapshot := c.Snapshot()
+
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = "pgx"
+	}
+
+	bw := bufio.NewWriter(w)
+
+	counters := []struct {
+		name  string
+		help  string
+		value func(s *StatementStats) int64
+	}{
+		{"query_calls_total", "Number of query executions.", func(s *StatementStats) int64 { return s.Calls }},
+		{"query_errors_total", "Number of query executions that failed.", func(s *StatementStats) int64 { return s.Errors }},
+		{"query_rows_total", "Number of rows returned or affected by queries.", func(s *StatementStats) int64 { return s.Rows }},
+	}
+
+	for _, counter := range counters {
+		name := namespace + "_" + counter.name
+		writeHeader(bw, name, counter.help, "counter")
+		for i := range snapshot {
+			writeSample(bw, name, snapshot[i].Fingerprint, "", strconv.FormatInt(counter.value(&snapshot[i]), 10))
+		}
+	}
+
+	name := namespace + "_query_duration_seconds"
+	writeHeader(bw, name, "Latency of query executions.", "histogram")
+	for i := range snapshot {
+		s := &snapshot[i]
+		for j, b := range s.Buckets {
+			writeSample(bw, name+"_bucket", s.Fingerprint, formatSeconds(b), strconv.FormatInt(s.BucketCounts[j], 10))
+		}
+		writeSample(bw, name+"_bucket", s.Fingerprint, "+Inf", strconv.FormatInt(s.Calls, 10))
+		writeSample(bw, name+"_sum", s.Fingerprint, "", formatSeconds(s.TotalTime))
+		writeSample(bw, name+"_count", s.Fingerprint, "", strconv.FormatInt(s.Calls, 10))
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	w.WriteString("# HELP ")
+	w.WriteString(name)
+	w.WriteByte(' ')
+	w.WriteString(help)
+	w.WriteString("\n# TYPE ")
+	w.WriteString(name)
+	w.WriteByte(' ')
+	w.WriteString(typ)
+	w.WriteByte('\n')
+}
+
+func writeSample(w *bufio.Writer, name, query, le, value string) {
+	w.WriteString(name)
+	w.WriteString(`{query="`)
+	w.WriteString(escapeLabelValue(query))
+	w.WriteByte('"')
+	if le != "" {
+		w.WriteString(`,le="`)
+		w.WriteString(le)
+		w.WriteByte('"')
+	}
+	w.WriteString("} ")
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
@@ -0,0 +1,190 @@
+package tracemetrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/jackc/pgx/v5/tracemetrics"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultConnTestRunner pgxtest.ConnTestRunner
+
+func init() {
+	defaultConnTestRunner = pgxtest.DefaultConnTestRunner()
+	defaultConnTestRunner.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config, err := pgx.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+		require.NoError(t, err)
+		return config
+	}
+}
+
+func traceQuery(c *tracemetrics.Collector, sql string, commandTag string, err error) {
+	ctx := c.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+	c.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag(commandTag), Err: err})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "select * from t where id in (...) and name = ?", tracemetrics.Fingerprint("SELECT * FROM t WHERE id IN (1, 2, 3) AND name = 'x' -- comment"))
+	require.Equal(t, tracemetrics.Fingerprint("select * from t where id in ($1)"), tracemetrics.Fingerprint("select * from t where id in ($1, $2)"))
+}
+
+func TestCollectorSnapshot(t *testing.T) {
+	t.Parallel()
+
+	c := &tracemetrics.Collector{Buckets: []time.Duration{time.Hour}}
+	traceQuery(c, "select * from t where id = 1", "SELECT 1", nil)
+	traceQuery(c, "select * from t where id = $1", "SELECT 0", nil)
+	traceQuery(c, "select * from t where id = 'x'", "", errors.New("invalid input syntax"))
+	traceQuery(c, "update t set x = 1", "UPDATE 5", nil)
+
+	snapshot := c.Snapshot()
+	require.Len(t, snapshot, 2)
+
+	require.Equal(t, "select * from t where id = ?", snapshot[0].Fingerprint)
+	require.EqualValues(t, 3, snapshot[0].Calls)
+	require.EqualValues(t, 1, snapshot[0].Errors)
+	require.EqualValues(t, 1, snapshot[0].Rows)
+	require.Equal(t, []time.Duration{time.Hour}, snapshot[0].Buckets)
+	require.Equal(t, []int64{3}, snapshot[0].BucketCounts)
+	require.LessOrEqual(t, snapshot[0].MinTime, snapshot[0].MaxTime)
+	require.LessOrEqual(t, snapshot[0].MaxTime, snapshot[0].TotalTime)
+
+	require.Equal(t, "update t set x = ?", snapshot[1].Fingerprint)
+	require.EqualValues(t, 1, snapshot[1].Calls)
+	require.EqualValues(t, 0, snapshot[1].Errors)
+	require.EqualValues(t, 5, snapshot[1].Rows)
+
+	c.Reset()
+	require.Empty(t, c.Snapshot())
+}
+
+func TestCollectorMaxStatements(t *testing.T) {
+	t.Parallel()
+
+	c := &tracemetrics.Collector{MaxStatements: 1}
+	traceQuery(c, "select 1", "SELECT 1", nil)
+	traceQuery(c, "select 1 from t", "SELECT 1", nil)
+	traceQuery(c, "select 1 from u", "SELECT 1", nil)
+	traceQuery(c, "select 2", "SELECT 1", nil)
+
+	snapshot := c.Snapshot()
+	require.Len(t, snapshot, 2)
+	require.Equal(t, tracemetrics.OtherFingerprint, snapshot[0].Fingerprint)
+	require.EqualValues(t, 2, snapshot[0].Calls)
+	require.Equal(t, "select ?", snapshot[1].Fingerprint)
+	require.EqualValues(t, 2, snapshot[1].Calls)
+}
+
+func TestCollectorBatch(t *testing.T) {
+	t.Parallel()
+
+	c := &tracemetrics.Collector{}
+	ctx := c.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{})
+	c.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "insert into t values (1)", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	c.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "insert into t values (2)", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	c.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	snapshot := c.Snapshot()
+	require.Len(t, snapshot, 1)
+	require.Equal(t, "insert into t values (?)", snapshot[0].Fingerprint)
+	require.EqualValues(t, 2, snapshot[0].Calls)
+	require.EqualValues(t, 2, snapshot[0].Rows)
+}
+
+func TestCollectorWritePrometheus(t *testing.T) {
+	t.Parallel()
+
+	c := &tracemetrics.Collector{Buckets: []time.Duration{0, time.Hour}, Namespace: "app"}
+	traceQuery(c, `select "a\b" from t`, "SELECT 2", nil)
+
+	buf := &bytes.Buffer{}
+	err := c.WritePrometheus(buf)
+	require.NoError(t, err)
+
+	snapshot := c.Snapshot()
+	require.Len(t, snapshot, 1)
+	sum := snapshot[0].TotalTime.Seconds()
+
+	expected := `# HELP app_query_calls_total Number of query executions.
+# TYPE app_query_calls_total counter
+app_query_calls_total{query="select \"a\\b\" from t"} 1
+# HELP app_query_errors_total Number of query executions that failed.
+# TYPE app_query_errors_total counter
+app_query_errors_total{query="select \"a\\b\" from t"} 0
+# HELP app_query_rows_total Number of rows returned or affected by queries.
+# TYPE app_query_rows_total counter
+app_query_rows_total{query="select \"a\\b\" from t"} 2
+# HELP app_query_duration_seconds Latency of query executions.
+# TYPE app_query_duration_seconds histogram
+app_query_duration_seconds_bucket{query="select \"a\\b\" from t",le="0"} 0
+app_query_duration_seconds_bucket{query="select \"a\\b\" from t",le="3600"} 1
+app_query_duration_seconds_bucket{query="select \"a\\b\" from t",le="+Inf"} 1
+app_query_duration_seconds_sum{query="select \"a\\b\" from t"} ` + formatFloat(sum) + `
+app_query_duration_seconds_count{query="select \"a\\b\" from t"} 1
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func TestCollectorTracesQueries(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	collector := &tracemetrics.Collector{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = collector
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		collector.Reset()
+
+		for i := 0; i < 3; i++ {
+			_, err := conn.Exec(ctx, "select $1::int4", i)
+			require.NoError(t, err)
+		}
+
+		rows, _ := conn.Query(ctx, "select n from generate_series(1, 5) n where n in (1, 2, 3)")
+		_, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+
+		_, err = conn.Exec(ctx, "select 1 / 0")
+		require.Error(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+		err = conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		stats := map[string]tracemetrics.StatementStats{}
+		for _, s := range collector.Snapshot() {
+			stats[s.Fingerprint] = s
+		}
+
+		require.EqualValues(t, 3, stats["select ?::int4"].Calls)
+		require.EqualValues(t, 1, stats["select n from generate_series(?, ?) n where n in (...)"].Calls)
+		require.EqualValues(t, 3, stats["select n from generate_series(?, ?) n where n in (...)"].Rows)
+		require.EqualValues(t, 1, stats["select ? / ?"].Errors)
+		require.EqualValues(t, 2, stats["select ?"].Calls)
+	})
+}
@@ -25,6 +25,19 @@ type TraceQueryEndData struct {
 	Err        error
 }
 
+// QueryRetryTracer traces queries that are retried. A query executed with QueryExecModeCacheStatement outside of a
+// transaction is retried once when its cached prepared statement became invalid because of a schema change.
+type QueryRetryTracer interface {
+	// TraceQueryRetry is called after the failed cached statement was deallocated and before the query is prepared and
+	// executed again. ctx is the context returned by TraceQueryStart.
+	TraceQueryRetry(ctx context.Context, conn *Conn, data TraceQueryRetryData)
+}
+
+type TraceQueryRetryData struct {
+	SQL string
+	Err error
+}
+
 // BatchTracer traces SendBatch.
 type BatchTracer interface {
 	// TraceBatchStart is called at the beginning of SendBatch calls. The returned context is used for the
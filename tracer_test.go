@@ -2,10 +2,12 @@ package pgx_test
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/require"
 )
@@ -13,6 +15,7 @@ import (
 type testTracer struct {
 	traceQueryStart    func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context
 	traceQueryEnd      func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData)
+	traceQueryRetry    func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryRetryData)
 	traceBatchStart    func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context
 	traceBatchQuery    func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData)
 	traceBatchEnd      func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData)
@@ -39,6 +42,12 @@ func (tt *testTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pg
 	}
 }
 
+func (tt *testTracer) TraceQueryRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryRetryData) {
+	if tt.traceQueryRetry != nil {
+		tt.traceQueryRetry(ctx, conn, data)
+	}
+}
+
 func (tt *testTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
 	if tt.traceBatchStart != nil {
 		return tt.traceBatchStart(ctx, conn, data)
@@ -179,6 +188,107 @@ func TestTraceQuery(t *testing.T) {
 	})
 }
 
+func TestTraceQueryRetry(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.Tracer = tracer
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(a int4)`)
+	mustExec(t, conn, `insert into foo values (1)`)
+
+	var retries []pgx.TraceQueryRetryData
+	tracer.traceQueryRetry = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryRetryData) {
+		retries = append(retries, data)
+	}
+
+	rows, err := conn.Query(ctx, `select * from foo where a = $1`, 1)
+	require.NoError(t, err)
+	values, err := pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"a": int32(1)}}, values)
+	require.Empty(t, retries)
+
+	mustExec(t, conn, `alter table foo add column b text default 'x'`)
+
+	rows, err = conn.Query(ctx, `select * from foo where a = $1`, 1)
+	require.NoError(t, err)
+	values, err = pgx.CollectRows(rows, pgx.RowToMap)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"a": int32(1), "b": "x"}}, values)
+	require.Len(t, retries, 1)
+	require.Equal(t, `select * from foo where a = $1`, retries[0].SQL)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, retries[0].Err, &pgErr)
+	require.Equal(t, "0A000", pgErr.Code)
+
+	_, err = conn.Exec(ctx, `update foo set b = $1 returning *`, "x")
+	require.NoError(t, err)
+
+	mustExec(t, conn, `alter table foo add column c text default 'y'`)
+
+	commandTag, err := conn.Exec(ctx, `update foo set b = $1 returning *`, "z")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, commandTag.RowsAffected())
+	require.Len(t, retries, 2)
+	require.Equal(t, `update foo set b = $1 returning *`, retries[1].SQL)
+
+	ensureConnValid(t, conn)
+}
+
+func TestTraceQueryNoRetryInTransaction(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.Tracer = tracer
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(a int4)`)
+	mustExec(t, conn, `insert into foo values (1)`)
+
+	retried := false
+	tracer.traceQueryRetry = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryRetryData) {
+		retried = true
+	}
+
+	var n int32
+	err := conn.QueryRow(ctx, `select * from foo where a = $1`, 1).Scan(&n)
+	require.NoError(t, err)
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `alter table foo add column b text`)
+	require.NoError(t, err)
+
+	rows, _ := tx.Query(ctx, `select * from foo where a = $1`, 1)
+	rows.Close()
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, rows.Err(), &pgErr)
+	require.Equal(t, "0A000", pgErr.Code)
+	require.False(t, retried)
+
+	require.NoError(t, tx.Rollback(ctx))
+
+	ensureConnValid(t, conn)
+}
+
 func TestTraceBatchNormal(t *testing.T) {
 	t.Parallel()
 
@@ -154,6 +154,7 @@ type dbTx struct {
 	conn         *Conn
 	savepointNum int64
 	closed       bool
+	cursors      []*Cursor
 }
 
 // Begin starts a pseudo nested transaction implemented with a savepoint.
@@ -179,6 +180,8 @@ func (tx *dbTx) Commit(ctx context.Context) error {
 
 	commandTag, err := tx.conn.Exec(ctx, "commit")
 	tx.closed = true
+	closeCursorsAtTxEnd(tx.cursors, err == nil && commandTag.String() != "ROLLBACK", tx.conn)
+	tx.cursors = nil
 	if err != nil {
 		if tx.conn.PgConn().TxStatus() != 'I' {
 			_ = tx.conn.Close(ctx) // already have error to return
@@ -203,6 +206,8 @@ func (tx *dbTx) Rollback(ctx context.Context) error {
 
 	_, err := tx.conn.Exec(ctx, "rollback")
 	tx.closed = true
+	closeCursorsAtTxEnd(tx.cursors, false, tx.conn)
+	tx.cursors = nil
 	if err != nil {
 		// A rollback failure leaves the connection in an undefined state
 		tx.conn.die(fmt.Errorf("rollback failed: %w", err))
@@ -274,11 +279,17 @@ func (tx *dbTx) Conn() *Conn {
 	return tx.conn
 }
 
+// RegisterCursor implements CursorRegistry.
+func (tx *dbTx) RegisterCursor(cursor *Cursor) {
+	tx.cursors = append(tx.cursors, cursor)
+}
+
 // dbSimulatedNestedTx represents a simulated nested transaction implemented by a savepoint.
 type dbSimulatedNestedTx struct {
 	tx           Tx
 	savepointNum int64
 	closed       bool
+	cursors      []*Cursor
 }
 
 // Begin starts a pseudo nested transaction implemented with a savepoint.
@@ -298,6 +309,13 @@ func (sp *dbSimulatedNestedTx) Commit(ctx context.Context) error {
 
 	_, err := sp.Exec(ctx, "release savepoint sp_"+strconv.FormatInt(sp.savepointNum, 10))
 	sp.closed = true
+	if r, ok := sp.tx.(CursorRegistry); ok && err == nil {
+		for _, c := range sp.cursors {
+			c.db = sp.tx
+			r.RegisterCursor(c)
+		}
+	}
+	sp.cursors = nil
 	return err
 }
 
@@ -311,6 +329,8 @@ func (sp *dbSimulatedNestedTx) Rollback(ctx context.Context) error {
 
 	_, err := sp.Exec(ctx, "rollback to savepoint sp_"+strconv.FormatInt(sp.savepointNum, 10))
 	sp.closed = true
+	closeCursorsAtTxEnd(sp.cursors, false, nil)
+	sp.cursors = nil
 	return err
 }
 
@@ -375,6 +395,11 @@ func (sp *dbSimulatedNestedTx) Conn() *Conn {
 	return sp.tx.Conn()
 }
 
+// RegisterCursor implements CursorRegistry.
+func (sp *dbSimulatedNestedTx) RegisterCursor(cursor *Cursor) {
+	sp.cursors = append(sp.cursors, cursor)
+}
+
 // BeginFunc calls Begin on db and then calls fn. If fn does not return an error then it calls Commit on db. If fn
 // returns an error it calls Rollback on db. The context will be used when executing the transaction control statements
 // (BEGIN, ROLLBACK, and COMMIT) but does not otherwise affect the execution of fn.